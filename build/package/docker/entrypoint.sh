@@ -1,3 +1,5 @@
 #!bin/sh
 
-sh -c /app/game-project
\ No newline at end of file
+# exec replaces the shell, so that the application receives SIGTERM itself and
+# can drain its requests when the container stops.
+exec /app/game-project "$@"
@@ -1,56 +1,239 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 
 	log "github.com/sirupsen/logrus"
 
+	"game-project/internal/adapters/http/auth"
 	"game-project/internal/adapters/http/handler"
+	"game-project/internal/adapters/http/middleware"
+	"game-project/internal/adapters/http/openapi"
+	"game-project/internal/adapters/http/ratelimit"
+	"game-project/internal/adapters/memory"
+	"game-project/internal/adapters/metrics"
 	"game-project/internal/adapters/postgresql"
+	"game-project/internal/adapters/tracing"
+	"game-project/internal/application"
+	"game-project/internal/config"
+	"game-project/internal/domain"
 
+	"github.com/gofrs/uuid"
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/gorilla/mux"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 type ApplicationHandler struct {
-	UserHandler  handler.UserHandler
+	UserHandler    handler.UserHandler
+	AuthHandler    handler.AuthHandler
+	HealthHandler  *handler.HealthHandler
+	AdminHandler   handler.AdminHandler
+	ServerVerifier *auth.ServerVerifier
+	RateLimiter    *ratelimit.Limiter
+	Metrics        *metrics.Metrics
+	// Validator is optional, requests are only checked against the OpenAPI
+	// document when it is set.
+	Validator *openapi.Validator
 }
 
-func NewApplicationHandler(u handler.UserHandler) ApplicationHandler {
+func NewApplicationHandler(u handler.UserHandler, a handler.AuthHandler, h *handler.HealthHandler, ad handler.AdminHandler, v *auth.ServerVerifier, l *ratelimit.Limiter, m *metrics.Metrics, val *openapi.Validator) ApplicationHandler {
 	return ApplicationHandler{
-		UserHandler: u,
+		UserHandler:    u,
+		AuthHandler:    a,
+		HealthHandler:  h,
+		AdminHandler:   ad,
+		ServerVerifier: v,
+		RateLimiter:    l,
+		Metrics:        m,
+		Validator:      val,
 	}
 }
 
 func Router(appHandler ApplicationHandler) *mux.Router {
 	r := mux.NewRouter()
-	r.HandleFunc("/user", appHandler.UserHandler.List).Methods("GET")
-	r.HandleFunc("/user", appHandler.UserHandler.Create).Methods("POST")
-	r.HandleFunc("/user/{userId}/state", appHandler.UserHandler.UpdateUserState).Methods("PUT")
-	r.HandleFunc("/user/{userId}/state", appHandler.UserHandler.LoadUserState).Methods("GET")
-	r.HandleFunc("/user/{userId}/friends", appHandler.UserHandler.UpdateUserFriends).Methods("PUT")
-	r.HandleFunc("/user/{userId}/friends", appHandler.UserHandler.ListUserFriends).Methods("GET")
+	r.Use(tracing.Middleware)
+	r.Use(appHandler.Metrics.Middleware)
+	r.Use(appHandler.ServerVerifier.Middleware)
+	r.Use(appHandler.AuthHandler.Authenticator.Middleware)
+	r.Use(middleware.AccessLog)
+	r.Use(appHandler.RateLimiter.Middleware)
+	if appHandler.Validator != nil {
+		r.Use(appHandler.Validator.Middleware)
+	}
+	r.HandleFunc("/healthz", appHandler.HealthHandler.Live).Methods("GET").Name("healthz")
+	r.HandleFunc("/readyz", appHandler.HealthHandler.Ready).Methods("GET").Name("readyz")
+	r.Handle("/metrics", appHandler.Metrics.Handler()).Methods("GET").Name("metrics")
+	r.HandleFunc("/openapi.json", openapi.Handler).Methods("GET").Name("openapi")
+	r.HandleFunc("/auth/token", appHandler.AuthHandler.IssueToken).Methods("POST").Name("issueToken")
+	r.HandleFunc("/user", appHandler.UserHandler.List).Methods("GET").Name("listUsers")
+	r.HandleFunc("/user", appHandler.UserHandler.Create).Methods("POST").Name("createUser")
+	// Registered before /user/{userId}, which would match it as well.
+	r.HandleFunc("/user/search", appHandler.UserHandler.SearchUsers).Methods("GET").Name("searchUsers")
+	r.HandleFunc("/user/{userId}", appHandler.UserHandler.GetUser).Methods("GET").Name("getUser")
+	r.HandleFunc("/user/{userId}", auth.RequireOwner(appHandler.UserHandler.UpdateUserProfile)).Methods("PATCH").Name("updateUserProfile")
+	r.HandleFunc("/user/{userId}", auth.RequireOwner(appHandler.UserHandler.DeleteUser)).Methods("DELETE").Name("deleteUser")
+	r.HandleFunc("/user/{userId}/secret", auth.RequireAdmin(appHandler.AuthHandler.ResetUserSecret)).Methods("POST").Name("resetUserSecret")
+	r.HandleFunc("/user/{userId}/state", auth.RequireOwnerOrServer(appHandler.UserHandler.UpdateUserState)).Methods("PUT").Name("updateUserState")
+	r.HandleFunc("/user/{userId}/state", appHandler.UserHandler.LoadUserState).Methods("GET").Name("loadUserState")
+	r.HandleFunc("/user/{userId}/sessions", appHandler.UserHandler.ListSessions).Methods("GET").Name("listSessions")
+	r.HandleFunc("/user/{userId}/friends", auth.RequireAdmin(appHandler.UserHandler.UpdateUserFriends)).Methods("PUT").Name("updateUserFriends")
+	r.HandleFunc("/user/{userId}/friends", auth.RequireAdmin(appHandler.UserHandler.PatchUserFriends)).Methods("PATCH").Name("patchUserFriends")
+	r.HandleFunc("/user/{userId}/friends/{friendId}", auth.RequireOwner(appHandler.UserHandler.RemoveUserFriend)).Methods("DELETE").Name("removeUserFriend")
+	r.HandleFunc("/user/{userId}/friends", appHandler.UserHandler.ListUserFriends).Methods("GET").Name("listUserFriends")
+	r.HandleFunc("/user/{userId}/friends/leaderboard", appHandler.UserHandler.FriendsLeaderboard).Methods("GET").Name("friendsLeaderboard")
+	r.HandleFunc("/user/{userId}/friend-requests", auth.RequireOwner(appHandler.UserHandler.SendFriendRequest)).Methods("POST").Name("sendFriendRequest")
+	r.HandleFunc("/user/{userId}/friend-requests", auth.RequireOwner(appHandler.UserHandler.ListFriendRequests)).Methods("GET").Name("listFriendRequests")
+	r.HandleFunc("/user/{userId}/friend-requests/{requestId}/accept", auth.RequireOwner(appHandler.UserHandler.AcceptFriendRequest)).Methods("POST").Name("acceptFriendRequest")
+	r.HandleFunc("/user/{userId}/friend-requests/{requestId}/decline", auth.RequireOwner(appHandler.UserHandler.DeclineFriendRequest)).Methods("POST").Name("declineFriendRequest")
+	r.HandleFunc("/user/{userId}/friend-requests/{requestId}", auth.RequireOwner(appHandler.UserHandler.CancelFriendRequest)).Methods("DELETE").Name("cancelFriendRequest")
+	r.HandleFunc("/user/{userId}/blocks", auth.RequireOwner(appHandler.UserHandler.BlockUser)).Methods("POST").Name("blockUser")
+	r.HandleFunc("/user/{userId}/blocks", auth.RequireOwner(appHandler.UserHandler.ListBlocks)).Methods("GET").Name("listBlocks")
+	r.HandleFunc("/user/{userId}/blocks/{blockedId}", auth.RequireOwner(appHandler.UserHandler.UnblockUser)).Methods("DELETE").Name("unblockUser")
+	r.HandleFunc("/leaderboard", appHandler.UserHandler.Leaderboard).Methods("GET").Name("leaderboard")
+	r.HandleFunc("/leaderboard/user/{userId}", appHandler.UserHandler.UserRank).Methods("GET").Name("userRank")
+	r.HandleFunc("/admin/log-level", auth.RequireAdmin(appHandler.AdminHandler.LogLevel)).Methods("GET").Name("logLevel")
+	r.HandleFunc("/admin/log-level", auth.RequireAdmin(appHandler.AdminHandler.SetLogLevel)).Methods("PUT").Name("setLogLevel")
 
 	log.Info("Application routers succesfully configured")
 
 	return r
 }
 
-func main() {
-	log.Info("Starting application")
-	host := os.Getenv("pgHost")
-	if host == "" {
-		host = "localhost"
+// newAuthenticator configures token signing. Signing keys are given as
+// "kid:secret" pairs; listing an older key next to the active one keeps the
+// tokens it signed valid while they are rotated out.
+func newAuthenticator(cfg config.Auth) *auth.Authenticator {
+	var keys *auth.KeySet
+	var err error
+	if cfg.SigningKeys != "" {
+		keys, err = auth.ParseKeySet(cfg.SigningKeys, cfg.ActiveKid)
+	} else {
+		log.Warn("No authSigningKeys configured, using an ephemeral key. Tokens will not survive restarts")
+		keys, err = auth.EphemeralKeySet()
+	}
+	if err != nil {
+		log.Fatal("invalid token signing keys: ", err)
 	}
 
-	m, err := migrate.New(
-		"file://data/migrations",
-		fmt.Sprintf("postgresql://root:root@%s:5432/game?sslmode=disable", host),
-	)
+	var admins []uuid.UUID
+	for _, id := range cfg.AdminIds {
+		adminId, err := uuid.FromString(id)
+		if err != nil {
+			log.Fatal("invalid authAdminIds: ", err)
+		}
+		admins = append(admins, adminId)
+	}
+
+	return auth.NewAuthenticator(keys, cfg.TokenTTL, admins)
+}
+
+// newServerVerifier configures which game servers may sign requests, given
+// as "serverId:secret" pairs, and which routes, by name, require a signature.
+func newServerVerifier(cfg config.GameServers) *auth.ServerVerifier {
+	secrets, err := auth.ParseServerSecrets(cfg.Secrets)
+	if err != nil {
+		log.Fatal("invalid gameServerSecrets: ", err)
+	}
+	if len(cfg.SignedRoutes) > 0 && len(secrets) == 0 {
+		log.Warn("signedRoutes are configured but no gameServerSecrets, these routes will reject every request")
+	}
+
+	return auth.NewServerVerifier(secrets, cfg.SignedRoutes, cfg.MaxSkew, auth.NewMemoryNonceStore())
+}
+
+// newRateLimiter limits the routes, by name, to the configured
+// routeName:requests/period[:burst] limits.
+func newRateLimiter(cfg config.RateLimits, store ratelimit.Store) *ratelimit.Limiter {
+	limits, err := ratelimit.ParseLimits(cfg.Routes)
+	if err != nil {
+		log.Fatal("invalid rateLimits: ", err)
+	}
+	log.Infof("Rate limiting %d routes with the %s store", len(limits), cfg.Store)
+
+	return ratelimit.NewLimiter(store, limits)
+}
+
+// newValidator loads the OpenAPI document to check the requests against, or
+// returns nil when requests are not validated.
+func newValidator(cfg config.HTTP) *openapi.Validator {
+	if !cfg.ValidateRequests {
+		return nil
+	}
+	doc, err := openapi.Load()
+	if err != nil {
+		log.Fatal("invalid OpenAPI document: ", err)
+	}
+	validator, err := openapi.NewValidator(doc)
+	if err != nil {
+		log.Fatal("could not route the OpenAPI document: ", err)
+	}
+	log.Info("Validating requests against the OpenAPI document")
+
+	return validator
+}
+
+// setupLogging applies the configured format and level to the standard
+// logger, every request logger derives from it.
+func setupLogging(cfg config.Logging) {
+	if cfg.Format == "text" {
+		log.SetFormatter(&log.TextFormatter{FullTimestamp: true})
+	} else {
+		log.SetFormatter(&log.JSONFormatter{})
+	}
+	// The level was already checked when the configuration was validated.
+	level, _ := log.ParseLevel(cfg.Level)
+	log.SetLevel(level)
+}
+
+// setupTracing installs the configured span exporter, and returns the
+// function that flushes it.
+func setupTracing(cfg config.Tracing) func(ctx context.Context) error {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case config.TracingStdout:
+		exporter, err = tracing.StdoutExporter()
+	case config.TracingOTLP:
+		exporter, err = tracing.OTLPExporter(context.Background(), cfg.Endpoint)
+	}
+	if err != nil {
+		log.Fatal("could not create the span exporter: ", err)
+	}
+	if exporter != nil {
+		log.Infof("Exporting spans with the %s exporter", cfg.Exporter)
+	}
+
+	return tracing.Setup(exporter)
+}
+
+// storage is the configured UserRepository and rate limit store, with the
+// readiness checks of the dependencies they rely on and the function that
+// releases them.
+type storage struct {
+	userRepository domain.UserRepository
+	rateLimitStore ratelimit.Store
+	checks         map[string]handler.Check
+	close          func()
+}
+
+// newStorage opens the configured storage, migrating the database first.
+// The statistics of the database pool are added to the metrics.
+func newStorage(cfg *config.Config, appMetrics *metrics.Metrics) storage {
+	if cfg.Storage == config.StorageMemory {
+		log.Warn("Using the memory storage, every change is lost when the application stops")
+		return storage{userRepository: memory.NewUserRepository(), rateLimitStore: ratelimit.NewMemoryStore(), close: func() {}}
+	}
+
+	m, err := migrate.New("file://"+cfg.Database.Migrations, cfg.Database.URL())
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -62,12 +245,108 @@ func main() {
 		}
 	}
 
-	pool := postgresql.CreatePool(host)
+	pool := postgresql.CreatePool(cfg.Database.URL(), int32(cfg.Database.MaxConns))
+	appMetrics.RegisterPool(pool)
+	userRepository := postgresql.NewUserRepository(pool, postgresql.Timeouts{
+		Read:  cfg.Database.ReadTimeout,
+		Write: cfg.Database.WriteTimeout,
+	})
+
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimits.Store == config.StoragePostgres {
+		rateLimitStore = postgresql.NewRateLimitStore(pool, cfg.Database.WriteTimeout)
+	}
 
-	userRepository := postgresql.NewUserRepository(pool)
+	return storage{
+		userRepository: userRepository,
+		rateLimitStore: rateLimitStore,
+		checks: map[string]handler.Check{
+			"postgres": postgresql.Ping(pool),
+			"schema":   postgresql.CheckSchema(pool),
+		},
+		close: pool.Close,
+	}
+}
 
-	appHandler := NewApplicationHandler(handler.NewUserHandler(userRepository))
+func main() {
+	log.Info("Starting application")
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if err != nil {
+		log.Fatal("invalid configuration: ", err)
+	}
+	setupLogging(cfg.Logging)
+	log.Infof("Using the %s profile", cfg.Profile)
+
+	// The timezone was already checked when the configuration was validated.
+	leaderboardLocation, _ := time.LoadLocation(cfg.Leaderboard.Timezone)
+
+	shutdownTracing := setupTracing(cfg.Tracing)
+	appMetrics := metrics.New()
+	store := newStorage(cfg, appMetrics)
+	userService := application.NewTracedUserService(application.NewUserService(store.userRepository, application.Config{
+		LeaderboardLocation:  leaderboardLocation,
+		RenameCooldown:       cfg.Users.RenameCooldown,
+		MaxFriendsPerRequest: cfg.Users.MaxFriendsPerRequest,
+		Metrics:              appMetrics,
+	}))
+
+	appHandler := NewApplicationHandler(
+		handler.NewUserHandler(userService),
+		handler.NewAuthHandler(userService, newAuthenticator(cfg.Auth)),
+		handler.NewHealthHandler(store.checks),
+		handler.NewAdminHandler(log.StandardLogger()),
+		newServerVerifier(cfg.GameServers),
+		newRateLimiter(cfg.RateLimits, store.rateLimitStore),
+		appMetrics,
+		newValidator(cfg.HTTP),
+	)
 	router := Router(appHandler)
 
-	http.ListenAndServe(":8080", router)
-}
\ No newline at end of file
+	server := &http.Server{
+		Addr:              cfg.HTTP.Addr,
+		Handler:           middleware.RequestID(middleware.Recover(middleware.LimitBody(cfg.HTTP.MaxBodyBytes)(router))),
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+	serve(server, cfg.HTTP.DrainDelay, cfg.HTTP.ShutdownTimeout, appHandler.HealthHandler.Drain)
+
+	store.close()
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		log.Warn("Could not flush the pending spans, error: ", err)
+	}
+	log.Info("Application stopped")
+}
+
+// serve runs the server until SIGINT or SIGTERM, then calls drain and keeps
+// serving for drainDelay, so that the failing readiness probe is noticed
+// before it stops accepting connections and waits up to shutdownTimeout for
+// in-flight requests.
+func serve(server *http.Server, drainDelay time.Duration, shutdownTimeout time.Duration, drain func()) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Infof("Listening on %s", server.Addr)
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		log.Fatal("the HTTP server stopped: ", err)
+	case <-ctx.Done():
+		log.Info("Shutting down, draining in-flight requests")
+	}
+	drain()
+	time.Sleep(drainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Warn("Could not drain every request before the shutdown timeout, error: ", err)
+	}
+}
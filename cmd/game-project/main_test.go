@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"game-project/internal/adapters/http/openapi"
+	"game-project/internal/adapters/metrics"
+)
+
+// TestRouter_OpenAPI fails when a route is missing from the OpenAPI
+// document, or when the document describes an operation the router lacks.
+func TestRouter_OpenAPI(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := Router(ApplicationHandler{Metrics: metrics.New()})
+
+	routed := map[string]bool{}
+	err = router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			routed[method+" "+template] = true
+			path := doc.Paths.Find(template)
+			if path == nil || path.GetOperation(method) == nil {
+				t.Errorf("route %s (%s %s) is missing from the OpenAPI document", route.GetName(), method, template)
+			} else if operationId := path.GetOperation(method).OperationID; operationId != route.GetName() {
+				t.Errorf("%s %s is named %s by the router but %s by the OpenAPI document", method, template, route.GetName(), operationId)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for template, path := range doc.Paths {
+		for method := range path.Operations() {
+			if !routed[method+" "+template] {
+				t.Errorf("the OpenAPI document describes %s %s, which is not routed", method, template)
+			}
+		}
+	}
+}
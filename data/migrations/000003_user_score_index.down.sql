@@ -0,0 +1 @@
+DROP INDEX IF EXISTS user_score_idx;
\ No newline at end of file
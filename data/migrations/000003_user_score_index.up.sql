@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS user_score_idx ON "user" ((COALESCE(score, 0)) DESC, name ASC);
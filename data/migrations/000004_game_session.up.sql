@@ -0,0 +1,12 @@
+CREATE table "game_session" (
+    id bigserial primary key,
+    user_id uuid not null REFERENCES public.user (id) ON DELETE CASCADE,
+    score int not null,
+    duration_ms int null,
+    metadata jsonb null,
+    submitted_at timestamptz not null default now()
+);
+
+CREATE INDEX IF NOT EXISTS game_session_user_idx ON "game_session" (user_id, submitted_at, score);
+CREATE INDEX IF NOT EXISTS game_session_period_idx ON "game_session" (submitted_at, user_id);
+CREATE INDEX IF NOT EXISTS game_session_history_idx ON "game_session" (user_id, submitted_at DESC, id DESC);
@@ -0,0 +1 @@
+ALTER TABLE "user" DROP COLUMN IF EXISTS secret_hash;
\ No newline at end of file
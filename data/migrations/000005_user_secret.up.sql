@@ -0,0 +1 @@
+ALTER TABLE "user" ADD COLUMN secret_hash bytea null;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "friend_request";
\ No newline at end of file
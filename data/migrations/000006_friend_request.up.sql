@@ -0,0 +1,15 @@
+CREATE table "friend_request" (
+    id uuid not null primary key,
+    from_user_id uuid not null REFERENCES public.user (id) ON DELETE CASCADE,
+    to_user_id uuid not null REFERENCES public.user (id) ON DELETE CASCADE,
+    status text not null default 'pending',
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    CONSTRAINT valid_status CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled')),
+    CONSTRAINT no_self_request CHECK (from_user_id <> to_user_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS friend_request_pending_idx ON "friend_request"
+    (LEAST(from_user_id, to_user_id), GREATEST(from_user_id, to_user_id)) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS friend_request_to_idx ON "friend_request" (to_user_id, status);
+CREATE INDEX IF NOT EXISTS friend_request_from_idx ON "friend_request" (from_user_id, status);
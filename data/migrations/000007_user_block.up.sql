@@ -0,0 +1,9 @@
+CREATE table "user_block" (
+    user_id uuid not null REFERENCES public.user (id) ON DELETE CASCADE,
+    blocked_id uuid not null REFERENCES public.user (id) ON DELETE CASCADE,
+    created_at timestamptz not null default now(),
+    PRIMARY KEY (user_id, blocked_id),
+    CONSTRAINT no_self_block CHECK (user_id <> blocked_id)
+);
+
+CREATE INDEX IF NOT EXISTS user_block_blocked_idx ON "user_block" (blocked_id);
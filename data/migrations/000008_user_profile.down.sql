@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "user_name_history";
+ALTER TABLE "user"
+    DROP COLUMN IF EXISTS country,
+    DROP COLUMN IF EXISTS avatar_url,
+    DROP COLUMN IF EXISTS bio,
+    DROP COLUMN IF EXISTS name_changed_at;
@@ -0,0 +1,14 @@
+ALTER TABLE "user"
+    ADD COLUMN country text null,
+    ADD COLUMN avatar_url text null,
+    ADD COLUMN bio text null,
+    ADD COLUMN name_changed_at timestamptz null;
+
+CREATE table "user_name_history" (
+    id bigserial primary key,
+    user_id uuid not null REFERENCES public.user (id) ON DELETE CASCADE,
+    name text not null,
+    changed_at timestamptz not null default now()
+);
+
+CREATE INDEX IF NOT EXISTS user_name_history_user_idx ON "user_name_history" (user_id, changed_at DESC);
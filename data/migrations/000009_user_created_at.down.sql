@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS user_created_at_idx;
+DROP INDEX IF EXISTS user_score_id_idx;
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,4 @@
+ALTER TABLE "user" ADD COLUMN created_at timestamptz not null default now();
+
+CREATE INDEX IF NOT EXISTS user_score_id_idx ON "user" ((COALESCE(score, 0)) DESC, id DESC);
+CREATE INDEX IF NOT EXISTS user_created_at_idx ON "user" (created_at DESC, id DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS user_name_trgm_idx;
+DROP INDEX IF EXISTS user_name_prefix_idx;
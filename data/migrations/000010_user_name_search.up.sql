@@ -0,0 +1,4 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS user_name_prefix_idx ON "user" (lower(name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS user_name_trgm_idx ON "user" USING gin (lower(name) gin_trgm_ops);
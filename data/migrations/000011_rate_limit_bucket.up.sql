@@ -0,0 +1,6 @@
+CREATE table "rate_limit_bucket" (
+    key text PRIMARY KEY,
+    full_at timestamptz not null
+);
+
+CREATE INDEX IF NOT EXISTS rate_limit_bucket_full_at_idx ON "rate_limit_bucket" (full_at);
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+
+	"game-project/internal/adapters/http/problem"
+	"game-project/internal/logging"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+type Claims struct {
+	jwt.StandardClaims
+	Role string `json:"role"`
+}
+
+func (c *Claims) IsAdmin() bool {
+	return c.Role == RoleAdmin
+}
+
+type claimsKey struct{}
+
+// ClaimsFromContext returns the claims of the bearer token the request was
+// authenticated with, if any.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
+
+type Authenticator struct {
+	keys     *KeySet
+	issuer   string
+	tokenTTL time.Duration
+	admins   map[uuid.UUID]bool
+}
+
+func NewAuthenticator(keys *KeySet, tokenTTL time.Duration, admins []uuid.UUID) *Authenticator {
+	adminSet := make(map[uuid.UUID]bool, len(admins))
+	for _, id := range admins {
+		adminSet[id] = true
+	}
+
+	return &Authenticator{keys: keys, issuer: "game-project", tokenTTL: tokenTTL, admins: adminSet}
+}
+
+// Issue signs a token for the given user with the active key. Users listed as
+// admins get the admin role.
+func (a *Authenticator) Issue(userId uuid.UUID) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(a.tokenTTL)
+	role := RoleUser
+	if a.admins[userId] {
+		role = RoleAdmin
+	}
+	claims := Claims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   userId.String(),
+			Issuer:    a.issuer,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiresAt.Unix(),
+		},
+		Role: role,
+	}
+
+	kid, key := a.keys.active()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+
+	return signed, expiresAt, err
+}
+
+func (a *Authenticator) Verify(tokenString string) (*Claims, error) {
+	claims := Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, ok := a.keys.lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return key, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims.Issuer != a.issuer {
+		return nil, errors.New("unexpected token issuer")
+	}
+
+	return &claims, nil
+}
+
+// Middleware authenticates requests carrying a bearer token and stores its
+// claims in the request context. Requests without an Authorization header go
+// through anonymously; it is up to RequireOwner to reject them.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		header := request.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(writer, request)
+			return
+		}
+		if !strings.HasPrefix(header, "Bearer ") {
+			writer.Header().Set("WWW-Authenticate", "Bearer")
+			problem.Write(writer, http.StatusUnauthorized, "invalid_token", "the Authorization header must hold a bearer token")
+			return
+		}
+		claims, err := a.Verify(strings.TrimPrefix(header, "Bearer "))
+		if err != nil {
+			logging.FromContext(request.Context()).Warn("Request with invalid token, error: ", err)
+			writer.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			problem.Write(writer, http.StatusUnauthorized, "invalid_token", "the bearer token is invalid or expired")
+			return
+		}
+
+		ctx := context.WithValue(request.Context(), claimsKey{}, claims)
+		next.ServeHTTP(writer, request.WithContext(ctx))
+	})
+}
+
+// RequireOwner only lets through requests authenticated as the user in the
+// {userId} route variable or as an admin.
+func RequireOwner(next http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		claims, ok := ClaimsFromContext(request.Context())
+		if !ok {
+			writer.Header().Set("WWW-Authenticate", "Bearer")
+			problem.Write(writer, http.StatusUnauthorized, "authentication_required", "the request must be authenticated")
+			return
+		}
+		userId := uuid.FromStringOrNil(mux.Vars(request)["userId"])
+		if !claims.IsAdmin() && claims.Subject != userId.String() {
+			logging.FromContext(request.Context()).Warnf("User %s is not allowed to act on behalf of user %s", claims.Subject, mux.Vars(request)["userId"])
+			problem.Write(writer, http.StatusForbidden, "not_owner", "the request may only act on behalf of the authenticated user")
+			return
+		}
+
+		next(writer, request)
+	}
+}
+
+// RequireOwnerOrServer is RequireOwner on the routes game servers report to,
+// it also lets through requests signed by a trusted game server.
+func RequireOwnerOrServer(next http.HandlerFunc) http.HandlerFunc {
+	owner := RequireOwner(next)
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if _, ok := ServerFromContext(request.Context()); ok {
+			next(writer, request)
+			return
+		}
+
+		owner(writer, request)
+	}
+}
+
+// RequireAdmin only lets through requests authenticated as an admin.
+func RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		claims, ok := ClaimsFromContext(request.Context())
+		if !ok {
+			writer.Header().Set("WWW-Authenticate", "Bearer")
+			problem.Write(writer, http.StatusUnauthorized, "authentication_required", "the request must be authenticated")
+			return
+		}
+		if !claims.IsAdmin() {
+			logging.FromContext(request.Context()).Warnf("User %s is not allowed to use an admin route", claims.Subject)
+			problem.Write(writer, http.StatusForbidden, "not_admin", "the request requires the admin role")
+			return
+		}
+
+		next(writer, request)
+	}
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+)
+
+func TestAuthenticator_KeyRotation(t *testing.T) {
+	userId, _ := uuid.NewV4()
+	oldKeys, _ := ParseKeySet("2021-08:old-secret", "")
+	oldAuthenticator := NewAuthenticator(oldKeys, time.Hour, nil)
+	oldToken, _, err := oldAuthenticator.Issue(userId)
+	if err != nil {
+		t.Fatalf("expected no err, actual: %s", err)
+	}
+
+	rotatedKeys, _ := ParseKeySet("2021-08:old-secret,2021-09:new-secret", "2021-09")
+	rotated := NewAuthenticator(rotatedKeys, time.Hour, nil)
+	claims, err := rotated.Verify(oldToken)
+	if err != nil {
+		t.Fatalf("token signed with a retired key should still verify, err: %s", err)
+	}
+	if claims.Subject != userId.String() || claims.Role != RoleUser {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+
+	newKeys, _ := ParseKeySet("2021-09:new-secret", "")
+	if _, err := NewAuthenticator(newKeys, time.Hour, nil).Verify(oldToken); err == nil {
+		t.Error("token signed with a removed key should not verify")
+	}
+}
+
+func TestAuthenticator_Verify(t *testing.T) {
+	userId, _ := uuid.NewV4()
+	keys, _ := ParseKeySet("k1:secret", "k1")
+	expired, _, _ := NewAuthenticator(keys, -time.Minute, nil).Issue(userId)
+	otherKeys, _ := ParseKeySet("k1:another-secret", "k1")
+	forged, _, _ := NewAuthenticator(otherKeys, time.Hour, nil).Issue(userId)
+
+	authenticator := NewAuthenticator(keys, time.Hour, nil)
+	for name, token := range map[string]string{"expired": expired, "forged": forged, "garbage": "not.a.token"} {
+		if _, err := authenticator.Verify(token); err == nil {
+			t.Errorf("%s token should not verify", name)
+		}
+	}
+}
+
+func TestParseKeySet(t *testing.T) {
+	cases := []struct {
+		spec      string
+		activeKid string
+		valid     bool
+	}{
+		{spec: "k1:secret", activeKid: "", valid: true},
+		{spec: "k1:secret, k2:other", activeKid: "k2", valid: true},
+		{spec: "k1:secret,k2:other", activeKid: "", valid: false},
+		{spec: "k1:secret", activeKid: "k2", valid: false},
+		{spec: "k1", activeKid: "", valid: false},
+		{spec: "", activeKid: "", valid: false},
+	}
+
+	for _, tc := range cases {
+		_, err := ParseKeySet(tc.spec, tc.activeKid)
+		if (err == nil) != tc.valid {
+			t.Errorf("spec %q with active kid %q: expected valid %t, err: %v", tc.spec, tc.activeKid, tc.valid, err)
+		}
+	}
+}
+
+func TestRequireOwner(t *testing.T) {
+	ownerId, _ := uuid.NewV4()
+	otherId, _ := uuid.NewV4()
+	adminId, _ := uuid.NewV4()
+	keys, _ := ParseKeySet("k1:secret", "")
+	authenticator := NewAuthenticator(keys, time.Hour, []uuid.UUID{adminId})
+	ownerToken, _, _ := authenticator.Issue(ownerId)
+	otherToken, _, _ := authenticator.Issue(otherId)
+	adminToken, _, _ := authenticator.Issue(adminId)
+
+	r := mux.NewRouter()
+	r.Use(authenticator.Middleware)
+	r.HandleFunc("/user/{userId}/state", RequireOwner(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	})).Methods("PUT")
+
+	cases := []struct {
+		authorization  string
+		expectedStatus int
+	}{
+		{authorization: "", expectedStatus: http.StatusUnauthorized},
+		{authorization: "Basic dXNlcjpwYXNz", expectedStatus: http.StatusUnauthorized},
+		{authorization: "Bearer garbage", expectedStatus: http.StatusUnauthorized},
+		{authorization: "Bearer " + otherToken, expectedStatus: http.StatusForbidden},
+		{authorization: "Bearer " + ownerToken, expectedStatus: http.StatusOK},
+		{authorization: "Bearer " + adminToken, expectedStatus: http.StatusOK},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/user/%s/state", ownerId), nil)
+		if tc.authorization != "" {
+			req.Header.Set("Authorization", tc.authorization)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tc.expectedStatus {
+			t.Errorf("authorization %q: expected status %d, actual: %d", tc.authorization, tc.expectedStatus, w.Code)
+		}
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	userId, _ := uuid.NewV4()
+	adminId, _ := uuid.NewV4()
+	keys, _ := ParseKeySet("k1:secret", "")
+	authenticator := NewAuthenticator(keys, time.Hour, []uuid.UUID{adminId})
+	userToken, _, _ := authenticator.Issue(userId)
+	adminToken, _, _ := authenticator.Issue(adminId)
+
+	r := mux.NewRouter()
+	r.Use(authenticator.Middleware)
+	r.HandleFunc("/admin/log-level", RequireAdmin(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	})).Methods("PUT")
+
+	cases := []struct {
+		authorization  string
+		expectedStatus int
+	}{
+		{authorization: "", expectedStatus: http.StatusUnauthorized},
+		{authorization: "Bearer " + userToken, expectedStatus: http.StatusForbidden},
+		{authorization: "Bearer " + adminToken, expectedStatus: http.StatusOK},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest("PUT", "/admin/log-level", nil)
+		if tc.authorization != "" {
+			req.Header.Set("Authorization", tc.authorization)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tc.expectedStatus {
+			t.Errorf("authorization %q: expected status %d, actual: %d", tc.authorization, tc.expectedStatus, w.Code)
+		}
+	}
+}
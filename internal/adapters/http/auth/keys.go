@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySet holds the HMAC keys tokens may be signed with, indexed by key id.
+// New tokens are always signed with the active key, while tokens signed with
+// any other key of the set keep verifying until that key is removed, which
+// allows rotating keys without logging everybody out.
+type KeySet struct {
+	keys      map[string][]byte
+	activeKid string
+}
+
+func NewKeySet(keys map[string][]byte, activeKid string) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one signing key is required")
+	}
+	if activeKid == "" && len(keys) == 1 {
+		for kid := range keys {
+			activeKid = kid
+		}
+	}
+	if _, ok := keys[activeKid]; !ok {
+		return nil, fmt.Errorf("active key id %q is not one of the signing keys", activeKid)
+	}
+
+	return &KeySet{keys: keys, activeKid: activeKid}, nil
+}
+
+// ParseKeySet builds a KeySet from a comma separated list of kid:secret
+// pairs, such as "2021-08:s3cr3t,2021-09:an0th3r".
+func ParseKeySet(spec string, activeKid string) (*KeySet, error) {
+	keys, err := parseSecrets(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeySet(keys, activeKid)
+}
+
+// EphemeralKeySet returns a KeySet with a single random key. Tokens signed
+// with it do not survive a restart.
+func EphemeralKeySet() (*KeySet, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return NewKeySet(map[string][]byte{"ephemeral": secret}, "ephemeral")
+}
+
+func (k *KeySet) active() (string, []byte) {
+	return k.activeKid, k.keys[k.activeKid]
+}
+
+func (k *KeySet) lookup(kid string) ([]byte, bool) {
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+// parseSecrets reads a comma separated list of id:secret pairs.
+func parseSecrets(spec string) (map[string][]byte, error) {
+	secrets := map[string][]byte{}
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid secret %q, expected id:secret", pair)
+		}
+		secrets[parts[0]] = []byte(parts[1])
+	}
+
+	return secrets, nil
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// NonceStore remembers the nonces of signed requests until they expire so
+// that a captured request cannot be replayed.
+type NonceStore interface {
+	// Remember records the nonce and reports whether it was unseen.
+	Remember(serverId string, nonce string, expiresAt time.Time) bool
+}
+
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	nextPrune time.Time
+	now       func() time.Time
+}
+
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{nonces: map[string]time.Time{}, now: time.Now}
+}
+
+func (s *MemoryNonceStore) Remember(serverId string, nonce string, expiresAt time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	key := serverId + "\n" + nonce
+	if exp, ok := s.nonces[key]; ok && exp.After(now) {
+		return false
+	}
+	s.nonces[key] = expiresAt
+
+	if now.After(s.nextPrune) {
+		for k, exp := range s.nonces {
+			if !exp.After(now) {
+				delete(s.nonces, k)
+			}
+		}
+		s.nextPrune = now.Add(time.Minute)
+	}
+
+	return true
+}
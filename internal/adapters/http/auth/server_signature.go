@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"game-project/internal/adapters/http/problem"
+	"game-project/internal/logging"
+)
+
+const (
+	HeaderServerId  = "X-Server-Id"
+	HeaderTimestamp = "X-Signature-Timestamp"
+	HeaderNonce     = "X-Signature-Nonce"
+	HeaderSignature = "X-Signature"
+
+	maxSignedBodySize = 1 << 20
+)
+
+type serverKey struct{}
+
+// ServerFromContext returns the id of the trusted game server that signed the
+// request, if the route requires a server signature.
+func ServerFromContext(ctx context.Context) (string, bool) {
+	serverId, ok := ctx.Value(serverKey{}).(string)
+	return serverId, ok
+}
+
+// ServerVerifier authenticates requests signed by trusted game servers. A
+// server signs a request by sending its id, the current unix timestamp, a
+// random nonce and the hex encoded HMAC-SHA256, keyed with its shared secret,
+// of the string built by SignatureBase.
+type ServerVerifier struct {
+	secrets map[string][]byte
+	routes  map[string]bool
+	maxSkew time.Duration
+	nonces  NonceStore
+	now     func() time.Time
+}
+
+// NewServerVerifier creates a verifier that requires a signature on the
+// routes with the given names and checks it on any other request that
+// carries one.
+func NewServerVerifier(secrets map[string][]byte, routes []string, maxSkew time.Duration, nonces NonceStore) *ServerVerifier {
+	routeSet := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		routeSet[route] = true
+	}
+
+	return &ServerVerifier{secrets: secrets, routes: routeSet, maxSkew: maxSkew, nonces: nonces, now: time.Now}
+}
+
+// ParseServerSecrets reads a comma separated list of serverId:secret pairs.
+func ParseServerSecrets(spec string) (map[string][]byte, error) {
+	return parseSecrets(spec)
+}
+
+// SignatureBase is the string a game server signs for a request.
+func SignatureBase(method string, requestURI string, timestamp string, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return fmt.Sprintf("%s\n%s\n%s\n%s\n%s", method, requestURI, timestamp, nonce, hex.EncodeToString(bodyHash[:]))
+}
+
+func Sign(secret []byte, base string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(base))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (v *ServerVerifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		required := false
+		if route := mux.CurrentRoute(request); route != nil {
+			required = v.routes[route.GetName()]
+		}
+		if request.Header.Get(HeaderSignature) == "" {
+			if required {
+				logging.FromContext(request.Context()).Warnf("Unsigned request to %s, which requires a game server signature", request.URL.Path)
+				problem.Write(writer, http.StatusUnauthorized, "signature_required", "the request must be signed by a game server")
+				return
+			}
+			next.ServeHTTP(writer, request)
+			return
+		}
+
+		serverId, err := v.verify(request)
+		if err != nil {
+			logging.FromContext(request.Context()).Warn("Request with invalid game server signature, error: ", err)
+			problem.Write(writer, http.StatusUnauthorized, "invalid_signature", "the game server signature is invalid")
+			return
+		}
+
+		// The server is only trusted on the routes it has to sign, elsewhere
+		// the request is handled like any other.
+		if !required {
+			next.ServeHTTP(writer, request)
+			return
+		}
+		ctx := context.WithValue(request.Context(), serverKey{}, serverId)
+		next.ServeHTTP(writer, request.WithContext(ctx))
+	})
+}
+
+func (v *ServerVerifier) verify(request *http.Request) (string, error) {
+	serverId := request.Header.Get(HeaderServerId)
+	secret, ok := v.secrets[serverId]
+	if !ok {
+		return "", fmt.Errorf("unknown game server %q", serverId)
+	}
+
+	timestamp := request.Header.Get(HeaderTimestamp)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", errors.New("invalid signature timestamp")
+	}
+	signedAt := time.Unix(unix, 0)
+	now := v.now()
+	if signedAt.Before(now.Add(-v.maxSkew)) || signedAt.After(now.Add(v.maxSkew)) {
+		return "", errors.New("signature timestamp outside of the allowed window")
+	}
+
+	nonce := request.Header.Get(HeaderNonce)
+	if nonce == "" {
+		return "", errors.New("missing signature nonce")
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, request.Body, maxSignedBodySize))
+	if err != nil {
+		return "", err
+	}
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	expected := Sign(secret, SignatureBase(request.Method, request.URL.RequestURI(), timestamp, nonce, body))
+	if !hmac.Equal([]byte(expected), []byte(request.Header.Get(HeaderSignature))) {
+		return "", errors.New("signature mismatch")
+	}
+	// Only remember nonces of genuine requests, so that forged requests
+	// cannot burn the nonces of legitimate ones. A nonce has to be kept
+	// for as long as its timestamp is acceptable.
+	if !v.nonces.Remember(serverId, nonce, signedAt.Add(v.maxSkew)) {
+		return "", errors.New("replayed signature nonce")
+	}
+
+	return serverId, nil
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+)
+
+func TestServerVerifier_Middleware(t *testing.T) {
+	userId, _ := uuid.NewV4()
+	secret := []byte("server-secret")
+	verifier := NewServerVerifier(map[string][]byte{"eu-1": secret}, []string{"updateUserState"}, time.Minute, NewMemoryNonceStore())
+	keys, _ := ParseKeySet("k1:secret", "")
+	authenticator := NewAuthenticator(keys, time.Hour, nil)
+
+	ok := func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}
+	r := mux.NewRouter()
+	r.Use(verifier.Middleware)
+	r.Use(authenticator.Middleware)
+	r.HandleFunc("/user/{userId}/state", RequireOwnerOrServer(ok)).Methods("PUT").Name("updateUserState")
+	r.HandleFunc("/user/{userId}/state", ok).Methods("GET").Name("loadUserState")
+	r.HandleFunc("/user/{userId}", RequireOwner(ok)).Methods("DELETE").Name("deleteUser")
+
+	statePath := fmt.Sprintf("/user/%s/state", userId)
+	userPath := fmt.Sprintf("/user/%s", userId)
+	otherId, _ := uuid.NewV4()
+	otherToken, _, _ := authenticator.Issue(otherId)
+	body := `{"gamesPlayed": 1, "score": 100}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	signature := func(method string, path string, timestamp string, nonce string, body string) string {
+		return Sign(secret, SignatureBase(method, path, timestamp, nonce, []byte(body)))
+	}
+
+	cases := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{
+			name:           "unsigned request to a signed route",
+			method:         "PUT",
+			body:           body,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "unsigned request to an open route",
+			method:         "GET",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "signed request",
+			method: "PUT",
+			body:   body,
+			headers: map[string]string{
+				HeaderServerId: "eu-1", HeaderTimestamp: now, HeaderNonce: "n1",
+				HeaderSignature: signature("PUT", statePath, now, "n1", body),
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "replayed request",
+			method: "PUT",
+			body:   body,
+			headers: map[string]string{
+				HeaderServerId: "eu-1", HeaderTimestamp: now, HeaderNonce: "n1",
+				HeaderSignature: signature("PUT", statePath, now, "n1", body),
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "tampered body",
+			method: "PUT",
+			body:   `{"gamesPlayed": 1, "score": 99999}`,
+			headers: map[string]string{
+				HeaderServerId: "eu-1", HeaderTimestamp: now, HeaderNonce: "n2",
+				HeaderSignature: signature("PUT", statePath, now, "n2", body),
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "stale timestamp",
+			method: "PUT",
+			body:   body,
+			headers: map[string]string{
+				HeaderServerId: "eu-1", HeaderTimestamp: stale, HeaderNonce: "n3",
+				HeaderSignature: signature("PUT", statePath, stale, "n3", body),
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "unknown server",
+			method: "PUT",
+			body:   body,
+			headers: map[string]string{
+				HeaderServerId: "us-1", HeaderTimestamp: now, HeaderNonce: "n4",
+				HeaderSignature: signature("PUT", statePath, now, "n4", body),
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "signed request to an owner route without a token",
+			method: "DELETE",
+			path:   userPath,
+			headers: map[string]string{
+				HeaderServerId: "eu-1", HeaderTimestamp: now, HeaderNonce: "n5",
+				HeaderSignature: signature("DELETE", userPath, now, "n5", ""),
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "signed request to an owner route as another user",
+			method: "DELETE",
+			path:   userPath,
+			headers: map[string]string{
+				HeaderServerId: "eu-1", HeaderTimestamp: now, HeaderNonce: "n6",
+				HeaderSignature: signature("DELETE", userPath, now, "n6", ""),
+				"Authorization": "Bearer " + otherToken,
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+	for _, tc := range cases {
+		path := tc.path
+		if path == "" {
+			path = statePath
+		}
+		req, _ := http.NewRequest(tc.method, path, strings.NewReader(tc.body))
+		for k, v := range tc.headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tc.expectedStatus {
+			t.Errorf("%s: expected status %d, actual: %d", tc.name, tc.expectedStatus, w.Code)
+		}
+	}
+}
+
+func TestMemoryNonceStore_Remember(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryNonceStore()
+	store.now = func() time.Time { return now }
+
+	if !store.Remember("eu-1", "n1", now.Add(time.Minute)) {
+		t.Fatal("first use of a nonce should be accepted")
+	}
+	if store.Remember("eu-1", "n1", now.Add(time.Minute)) {
+		t.Error("second use of a nonce should be rejected")
+	}
+	if !store.Remember("eu-2", "n1", now.Add(time.Minute)) {
+		t.Error("nonces should be scoped to the server")
+	}
+
+	store.now = func() time.Time { return now.Add(2 * time.Minute) }
+	if !store.Remember("eu-1", "n1", now.Add(3*time.Minute)) {
+		t.Error("expired nonces should be forgotten")
+	}
+}
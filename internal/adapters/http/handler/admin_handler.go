@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"game-project/internal/adapters/http/problem"
+	"game-project/internal/application/command"
+	"game-project/internal/application/query"
+	"game-project/internal/logging"
+)
+
+// AdminHandler serves the operational routes reserved to admins.
+type AdminHandler struct {
+	Logger *log.Logger
+}
+
+func NewAdminHandler(logger *log.Logger) AdminHandler {
+	return AdminHandler{Logger: logger}
+}
+
+func (h AdminHandler) LogLevel(writer http.ResponseWriter, request *http.Request) {
+	h.writeLogLevel(writer)
+}
+
+// SetLogLevel changes the level of the logger at runtime, until the next
+// restart.
+func (h AdminHandler) SetLogLevel(writer http.ResponseWriter, request *http.Request) {
+	var command command.SetLogLevel
+	err := json.NewDecoder(request.Body).Decode(&command)
+	if err != nil {
+		problem.Invalid(writer, "body", "must be a valid JSON document")
+		return
+	}
+	level, err := log.ParseLevel(command.Level)
+	if err != nil {
+		problem.Invalid(writer, "level", "must be one of panic, fatal, error, warn, info, debug or trace")
+		return
+	}
+
+	logging.FromContext(request.Context()).Warnf("Changing the log level from %s to %s", h.Logger.GetLevel(), level)
+	h.Logger.SetLevel(level)
+	h.writeLogLevel(writer)
+}
+
+func (h AdminHandler) writeLogLevel(writer http.ResponseWriter) {
+	res, _ := json.Marshal(query.LogLevel{Level: h.Logger.GetLevel().String()})
+
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(res)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+
+	"game-project/internal/application/query"
+)
+
+func TestAdminHandler_SetLogLevel(t *testing.T) {
+	cases := []struct {
+		body           string
+		expectedStatus int
+		expectedLevel  log.Level
+	}{
+		{body: `{"level": "debug"}`, expectedStatus: http.StatusOK, expectedLevel: log.DebugLevel},
+		{body: `{"level": "WARN"}`, expectedStatus: http.StatusOK, expectedLevel: log.WarnLevel},
+		{body: `{"level": "verbose"}`, expectedStatus: http.StatusBadRequest, expectedLevel: log.InfoLevel},
+		{body: `level=debug`, expectedStatus: http.StatusBadRequest, expectedLevel: log.InfoLevel},
+	}
+
+	for _, tc := range cases {
+		logger := log.New()
+		logger.SetLevel(log.InfoLevel)
+		handler := NewAdminHandler(logger)
+		r, _ := http.NewRequest("PUT", "/admin/log-level", strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		handler.SetLogLevel(w, r)
+
+		if w.Code != tc.expectedStatus {
+			t.Errorf("%s: expected status %d, actual: %d", tc.body, tc.expectedStatus, w.Code)
+		}
+		if logger.GetLevel() != tc.expectedLevel {
+			t.Errorf("%s: expected level %s, actual: %s", tc.body, tc.expectedLevel, logger.GetLevel())
+		}
+		if tc.expectedStatus == http.StatusOK {
+			var level query.LogLevel
+			json.Unmarshal(w.Body.Bytes(), &level)
+			if level.Level != tc.expectedLevel.String() {
+				t.Errorf("%s: expected the new level in the response, actual: %s", tc.body, level.Level)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+
+	"game-project/internal/adapters/http/auth"
+	"game-project/internal/adapters/http/problem"
+	"game-project/internal/application"
+	"game-project/internal/application/command"
+	"game-project/internal/application/query"
+	"game-project/internal/logging"
+)
+
+func NewAuthHandler(service application.UserService, authenticator *auth.Authenticator) AuthHandler {
+	return AuthHandler{Service: service, Authenticator: authenticator}
+}
+
+type AuthHandler struct {
+	Service       application.UserService
+	Authenticator *auth.Authenticator
+}
+
+func (h AuthHandler) IssueToken(writer http.ResponseWriter, request *http.Request) {
+	var command command.IssueToken
+	err := json.NewDecoder(request.Body).Decode(&command)
+	if err != nil {
+		problem.Invalid(writer, "body", "must be a valid JSON document")
+		return
+	}
+	logging.FromContext(request.Context()).Debugf("Received IssueToken request for user id: %s", command.UserId)
+
+	err = h.Service.Authenticate(request.Context(), command)
+	if err != nil {
+		logging.FromContext(request.Context()).Warnf("Could not authenticate user %s", command.UserId)
+		problem.WriteError(writer, err)
+		return
+	}
+	token, expiresAt, err := h.Authenticator.Issue(command.UserId)
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Could not sign token, error: ", err)
+		problem.WriteError(writer, err)
+		return
+	}
+
+	res, _ := json.Marshal(query.Token{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(expiresAt).Seconds()),
+	})
+
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(res)
+}
+
+// ResetUserSecret issues a new secret for the user, for admins to hand to
+// users who have none or lost theirs.
+func (h AuthHandler) ResetUserSecret(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, err := uuid.FromString(vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received ResetUserSecret request")
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
+		return
+	}
+
+	secret, err := h.Service.ResetUserSecret(request.Context(), id)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+
+	res, _ := json.Marshal(secret)
+
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(res)
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+
+	"game-project/internal/adapters/http/auth"
+	"game-project/internal/adapters/http/problem"
+	"game-project/internal/application"
+	"game-project/internal/application/query"
+	"game-project/internal/domain"
+)
+
+func TestAuthHandler_IssueToken(t *testing.T) {
+	keys, err := auth.EphemeralKeySet()
+	if err != nil {
+		t.Fatalf("expected no err, actual: %s", err)
+	}
+	cases := []struct {
+		name            string
+		fakeServiceImpl *fakeServiceImpl
+		body            string
+		expectedStatus  int
+		expectedCode    string
+	}{
+		{
+			name:            "valid secret",
+			fakeServiceImpl: &fakeServiceImpl{},
+			body:            `{"userId": "d4b9a5b8-4e9c-4a4e-9f6a-1f0e2a3b4c5d", "secret": "s3cret"}`,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			name:            "wrong secret",
+			fakeServiceImpl: &fakeServiceImpl{err: application.ErrInvalidCredentials},
+			body:            `{"userId": "d4b9a5b8-4e9c-4a4e-9f6a-1f0e2a3b4c5d", "secret": "wrong"}`,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedCode:    "invalid_credentials",
+		},
+		{
+			name:            "user without a secret",
+			fakeServiceImpl: &fakeServiceImpl{err: application.ErrSecretNotSet},
+			body:            `{"userId": "d4b9a5b8-4e9c-4a4e-9f6a-1f0e2a3b4c5d", "secret": ""}`,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedCode:    "secret_not_set",
+		},
+		{
+			name:            "invalid body",
+			fakeServiceImpl: &fakeServiceImpl{},
+			body:            `{"userId": `,
+			expectedStatus:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		handler := NewAuthHandler(tc.fakeServiceImpl, auth.NewAuthenticator(keys, time.Hour, nil))
+		r, _ := http.NewRequest("POST", "/auth/token", strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		handler.IssueToken(w, r)
+
+		if w.Code != tc.expectedStatus {
+			t.Errorf("%s: expected status %d, actual: %d", tc.name, tc.expectedStatus, w.Code)
+		}
+		if tc.expectedCode != "" {
+			var details problem.Details
+			json.Unmarshal(w.Body.Bytes(), &details)
+			if details.Code != tc.expectedCode {
+				t.Errorf("%s: expected code %s, actual: %s", tc.name, tc.expectedCode, details.Code)
+			}
+		}
+	}
+}
+
+func TestAuthHandler_ResetUserSecret(t *testing.T) {
+	userId := uuid.Must(uuid.NewV4())
+	cases := []struct {
+		name            string
+		fakeServiceImpl *fakeServiceImpl
+		userId          string
+		expectedStatus  int
+	}{
+		{
+			name:            "reset",
+			fakeServiceImpl: &fakeServiceImpl{userSecretResult: &query.UserSecret{Id: userId, Secret: "s3cret"}},
+			userId:          userId.String(),
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			name:            "unknown user",
+			fakeServiceImpl: &fakeServiceImpl{err: domain.NotFoundError("user_not_found", "no user found")},
+			userId:          userId.String(),
+			expectedStatus:  http.StatusNotFound,
+		},
+		{
+			name:            "invalid user id",
+			fakeServiceImpl: &fakeServiceImpl{},
+			userId:          "not-a-uuid",
+			expectedStatus:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		handler := NewAuthHandler(tc.fakeServiceImpl, nil)
+		r := mux.NewRouter()
+		r.HandleFunc("/user/{userId}/secret", handler.ResetUserSecret).Methods("POST")
+		req, _ := http.NewRequest("POST", "/user/"+tc.userId+"/secret", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tc.expectedStatus {
+			t.Errorf("%s: expected status %d, actual: %d", tc.name, tc.expectedStatus, w.Code)
+		}
+		if tc.expectedStatus == http.StatusOK {
+			if w.Header().Get("Cache-Control") != "no-store" {
+				t.Errorf("%s: expected the secret not to be cached", tc.name)
+			}
+			var secret query.UserSecret
+			decodeResult(w, &secret)
+			if secret.Secret != "s3cret" || secret.Id != userId {
+				t.Errorf("%s: expected the new secret in the response, actual: %+v", tc.name, secret)
+			}
+		}
+	}
+}
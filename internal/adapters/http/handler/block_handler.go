@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+
+	"game-project/internal/adapters/http/problem"
+	"game-project/internal/application/command"
+	"game-project/internal/logging"
+)
+
+func (h UserHandler) BlockUser(writer http.ResponseWriter, request *http.Request) {
+	var command command.BlockUser
+	vars := mux.Vars(request)
+	id, err := uuid.FromString(vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received BlockUser request")
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
+		return
+	}
+	err = json.NewDecoder(request.Body).Decode(&command)
+	if err != nil {
+		problem.Invalid(writer, "body", "must be a valid JSON document")
+		return
+	}
+
+	err = h.Service.BlockUser(request.Context(), id, command)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (h UserHandler) UnblockUser(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	logging.FromContext(request.Context()).Debugf("Received UnblockUser request for blocked id: %s", vars["blockedId"])
+	id, err := uuid.FromString(vars["userId"])
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
+		return
+	}
+	blockedId, err := uuid.FromString(vars["blockedId"])
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid blocked user UUID")
+		problem.Invalid(writer, "blockedId", "must be a UUID")
+		return
+	}
+
+	err = h.Service.UnblockUser(request.Context(), id, blockedId)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (h UserHandler) ListBlocks(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, err := uuid.FromString(vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received ListBlocks request")
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
+		return
+	}
+
+	blocks, err := h.Service.ListBlocks(request.Context(), id)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+	res, _ := json.Marshal(blocks)
+
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(res)
+}
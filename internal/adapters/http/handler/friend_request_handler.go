@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+
+	"game-project/internal/adapters/http/problem"
+	"game-project/internal/application/command"
+	"game-project/internal/domain"
+	"game-project/internal/logging"
+)
+
+func (h UserHandler) SendFriendRequest(writer http.ResponseWriter, request *http.Request) {
+	var command command.SendFriendRequest
+	vars := mux.Vars(request)
+	id, err := uuid.FromString(vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received SendFriendRequest request")
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
+		return
+	}
+	err = json.NewDecoder(request.Body).Decode(&command)
+	if err != nil {
+		problem.Invalid(writer, "body", "must be a valid JSON document")
+		return
+	}
+
+	friendRequest, err := h.Service.SendFriendRequest(request.Context(), id, command)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+	res, _ := json.Marshal(friendRequest)
+
+	writer.WriteHeader(http.StatusCreated)
+	writer.Write(res)
+}
+
+func (h UserHandler) ListFriendRequests(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, err := uuid.FromString(vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received ListFriendRequests request")
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
+		return
+	}
+	direction := domain.FriendRequestDirection(request.URL.Query().Get("direction"))
+	switch direction {
+	case "":
+		direction = domain.FriendRequestIncoming
+	case domain.FriendRequestIncoming, domain.FriendRequestOutgoing:
+	default:
+		logging.FromContext(request.Context()).Warnf("Request with invalid direction: %s", direction)
+		problem.Invalid(writer, "direction", "must be incoming or outgoing")
+		return
+	}
+
+	friendRequests, err := h.Service.ListFriendRequests(request.Context(), id, direction)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+	res, _ := json.Marshal(friendRequests)
+
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(res)
+}
+
+func (h UserHandler) AcceptFriendRequest(writer http.ResponseWriter, request *http.Request) {
+	h.resolveFriendRequest(writer, request, "AcceptFriendRequest", h.Service.AcceptFriendRequest)
+}
+
+func (h UserHandler) DeclineFriendRequest(writer http.ResponseWriter, request *http.Request) {
+	h.resolveFriendRequest(writer, request, "DeclineFriendRequest", h.Service.DeclineFriendRequest)
+}
+
+func (h UserHandler) CancelFriendRequest(writer http.ResponseWriter, request *http.Request) {
+	h.resolveFriendRequest(writer, request, "CancelFriendRequest", h.Service.CancelFriendRequest)
+}
+
+func (h UserHandler) resolveFriendRequest(writer http.ResponseWriter, request *http.Request, name string,
+	resolve func(ctx context.Context, userId uuid.UUID, requestId uuid.UUID) error) {
+	vars := mux.Vars(request)
+	logging.FromContext(request.Context()).Debugf("Received %s request for friend request id: %s", name, vars["requestId"])
+	id, err := uuid.FromString(vars["userId"])
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
+		return
+	}
+	requestId, err := uuid.FromString(vars["requestId"])
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid friend request UUID")
+		problem.Invalid(writer, "requestId", "must be a UUID")
+		return
+	}
+
+	err = resolve(request.Context(), id, requestId)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
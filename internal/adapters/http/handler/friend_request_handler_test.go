@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/application"
+)
+
+func TestUserHandler_ResolveFriendRequest(t *testing.T) {
+	cases := []struct {
+		fakeServiceImpl *fakeServiceImpl
+		method          string
+		path            string
+		expectedStatus  int
+	}{
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			method:          "POST",
+			path:            "/accept",
+			expectedStatus:  http.StatusNoContent,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{err: application.ErrFriendRequestNotPending},
+			method:          "POST",
+			path:            "/decline",
+			expectedStatus:  http.StatusConflict,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{err: application.ErrFriendRequestNotFound},
+			method:          "DELETE",
+			path:            "",
+			expectedStatus:  http.StatusNotFound,
+		},
+	}
+	for _, tc := range cases {
+		handler := &UserHandler{Service: tc.fakeServiceImpl}
+		userId, _ := uuid.NewV4()
+		requestId, _ := uuid.NewV4()
+		r, _ := http.NewRequest(tc.method, fmt.Sprintf("/user/%s/friend-requests/%s%s", userId, requestId, tc.path), nil)
+		w := httptest.NewRecorder()
+		router(handler).ServeHTTP(w, r)
+
+		if w.Code != tc.expectedStatus {
+			t.Fatalf("wrong status retrieved, should be %d and received %d instead", tc.expectedStatus, w.Code)
+		}
+	}
+
+	handler := &UserHandler{Service: &fakeServiceImpl{}}
+	userId, _ := uuid.NewV4()
+	r, _ := http.NewRequest("GET", fmt.Sprintf("/user/%s/friend-requests?direction=sideways", userId), nil)
+	w := httptest.NewRecorder()
+	router(handler).ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("wrong status retrieved, should be %d and received %d instead", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"game-project/internal/application/query"
+	"game-project/internal/logging"
+)
+
+const (
+	healthOk          = "ok"
+	healthUnavailable = "unavailable"
+	// readinessTimeout bounds all the readiness checks together, so that a
+	// hanging dependency fails the probe instead of timing it out.
+	readinessTimeout = 2 * time.Second
+)
+
+// Check tells whether a dependency is usable, returning why it is not.
+type Check func(ctx context.Context) error
+
+func NewHealthHandler(checks map[string]Check) *HealthHandler {
+	return &HealthHandler{checks: checks}
+}
+
+type HealthHandler struct {
+	checks   map[string]Check
+	draining int32
+}
+
+// Drain makes the readiness probe fail from now on, so that no new traffic
+// is routed to the application while it shuts down.
+func (h *HealthHandler) Drain() {
+	atomic.StoreInt32(&h.draining, 1)
+}
+
+// Live answers as long as the application serves requests.
+func (h *HealthHandler) Live(writer http.ResponseWriter, request *http.Request) {
+	writeHealth(writer, http.StatusOK, query.Health{Status: healthOk})
+}
+
+// Ready runs every check and answers 503 when any of them fails or when the
+// application is shutting down.
+func (h *HealthHandler) Ready(writer http.ResponseWriter, request *http.Request) {
+	if atomic.LoadInt32(&h.draining) == 1 {
+		writeHealth(writer, http.StatusServiceUnavailable, query.Health{Status: "draining"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), readinessTimeout)
+	defer cancel()
+
+	health := query.Health{Status: healthOk, Checks: make(map[string]string, len(h.checks))}
+	status := http.StatusOK
+	for name, check := range h.checks {
+		if err := check(ctx); err != nil {
+			logging.FromContext(request.Context()).Warnf("Readiness check %s failed, error: %v", name, err)
+			health.Checks[name] = healthUnavailable
+			health.Status = healthUnavailable
+			status = http.StatusServiceUnavailable
+			continue
+		}
+		health.Checks[name] = healthOk
+	}
+
+	writeHealth(writer, status, health)
+}
+
+func writeHealth(writer http.ResponseWriter, status int, health query.Health) {
+	res, _ := json.Marshal(health)
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.WriteHeader(status)
+	writer.Write(res)
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"game-project/internal/application/query"
+)
+
+func TestHealthHandler_Ready(t *testing.T) {
+	passing := func(ctx context.Context) error { return nil }
+	failing := func(ctx context.Context) error { return errors.New("connection refused") }
+	cases := []struct {
+		name           string
+		checks         map[string]Check
+		drain          bool
+		expectedStatus int
+		expectedHealth query.Health
+	}{
+		{
+			name:           "no dependencies",
+			expectedStatus: http.StatusOK,
+			expectedHealth: query.Health{Status: "ok"},
+		},
+		{
+			name:           "every check passes",
+			checks:         map[string]Check{"postgres": passing, "schema": passing},
+			expectedStatus: http.StatusOK,
+			expectedHealth: query.Health{Status: "ok", Checks: map[string]string{"postgres": "ok", "schema": "ok"}},
+		},
+		{
+			name:           "a check fails",
+			checks:         map[string]Check{"postgres": failing, "schema": passing},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedHealth: query.Health{Status: "unavailable", Checks: map[string]string{"postgres": "unavailable", "schema": "ok"}},
+		},
+		{
+			name:           "draining",
+			checks:         map[string]Check{"postgres": passing},
+			drain:          true,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedHealth: query.Health{Status: "draining"},
+		},
+	}
+
+	for _, tc := range cases {
+		handler := NewHealthHandler(tc.checks)
+		if tc.drain {
+			handler.Drain()
+		}
+		w := httptest.NewRecorder()
+		handler.Ready(w, httptest.NewRequest("GET", "/readyz", nil))
+
+		if w.Code != tc.expectedStatus {
+			t.Errorf("%s: expected status %d, actual: %d", tc.name, tc.expectedStatus, w.Code)
+		}
+		var health query.Health
+		json.Unmarshal(w.Body.Bytes(), &health)
+		if health.Status != tc.expectedHealth.Status || len(health.Checks) != len(tc.expectedHealth.Checks) {
+			t.Errorf("%s: expected %+v, actual: %+v", tc.name, tc.expectedHealth, health)
+			continue
+		}
+		for name, status := range tc.expectedHealth.Checks {
+			if health.Checks[name] != status {
+				t.Errorf("%s: expected %s to be %q, actual: %q", tc.name, name, status, health.Checks[name])
+			}
+		}
+	}
+}
+
+func TestHealthHandler_Live(t *testing.T) {
+	handler := NewHealthHandler(map[string]Check{"postgres": func(ctx context.Context) error { return errors.New("down") }})
+	handler.Drain()
+	w := httptest.NewRecorder()
+	handler.Live(w, httptest.NewRequest("GET", "/healthz", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected liveness to ignore dependencies and draining, actual status: %d", w.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+
+	"game-project/internal/adapters/http/problem"
+	"game-project/internal/application/command"
+	"game-project/internal/logging"
+)
+
+func (h UserHandler) GetUser(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, err := uuid.FromString(vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received GetUser request")
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
+		return
+	}
+
+	profile, err := h.Service.GetUserProfile(request.Context(), id)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+
+	res, _ := json.Marshal(profile)
+
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(res)
+}
+
+func (h UserHandler) UpdateUserProfile(writer http.ResponseWriter, request *http.Request) {
+	var command command.UpdateUserProfile
+	vars := mux.Vars(request)
+	id, err := uuid.FromString(vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received UpdateUserProfile request")
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
+		return
+	}
+	err = json.NewDecoder(request.Body).Decode(&command)
+	if err != nil {
+		problem.Invalid(writer, "body", "must be a valid JSON document")
+		return
+	}
+
+	profile, err := h.Service.UpdateUserProfile(request.Context(), id, command)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+
+	res, _ := json.Marshal(profile)
+
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(res)
+}
+
+func (h UserHandler) DeleteUser(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, err := uuid.FromString(vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received DeleteUser request")
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
+		return
+	}
+
+	err = h.Service.DeleteUser(request.Context(), id)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
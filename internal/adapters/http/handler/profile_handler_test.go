@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/application"
+	"game-project/internal/application/query"
+	"game-project/internal/domain"
+)
+
+func TestUserHandler_Profile(t *testing.T) {
+	cases := []struct {
+		name            string
+		fakeServiceImpl *fakeServiceImpl
+		method          string
+		body            string
+		expectedStatus  int
+	}{
+		{
+			name:            "get user",
+			fakeServiceImpl: &fakeServiceImpl{userProfileResult: &query.UserProfile{Name: "Jessica"}},
+			method:          "GET",
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			name:            "get unknown user",
+			fakeServiceImpl: &fakeServiceImpl{err: domain.NotFoundError("user_not_found", "no user found")},
+			method:          "GET",
+			expectedStatus:  http.StatusNotFound,
+		},
+		{
+			name:            "rename",
+			fakeServiceImpl: &fakeServiceImpl{userProfileResult: &query.UserProfile{Name: "Jessie"}},
+			method:          "PATCH",
+			body:            `{"name": "Jessie"}`,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			name:            "rename during the cooldown",
+			fakeServiceImpl: &fakeServiceImpl{err: application.ErrRenameCooldown},
+			method:          "PATCH",
+			body:            `{"name": "Jessie"}`,
+			expectedStatus:  http.StatusConflict,
+		},
+		{
+			name:            "invalid body",
+			fakeServiceImpl: &fakeServiceImpl{},
+			method:          "PATCH",
+			body:            `{"name": `,
+			expectedStatus:  http.StatusBadRequest,
+		},
+		{
+			name:            "delete user",
+			fakeServiceImpl: &fakeServiceImpl{},
+			method:          "DELETE",
+			expectedStatus:  http.StatusNoContent,
+		},
+	}
+	for _, tc := range cases {
+		handler := &UserHandler{Service: tc.fakeServiceImpl}
+		userId, _ := uuid.NewV4()
+		r, _ := http.NewRequest(tc.method, fmt.Sprintf("/user/%s", userId), strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		router(handler).ServeHTTP(w, r)
+
+		if w.Code != tc.expectedStatus {
+			t.Fatalf("%s: wrong status retrieved, should be %d and received %d instead", tc.name, tc.expectedStatus, w.Code)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/adapters/http/auth"
+	"game-project/internal/adapters/http/problem"
+	"game-project/internal/logging"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+)
+
+func (h UserHandler) SearchUsers(writer http.ResponseWriter, request *http.Request) {
+	logging.FromContext(request.Context()).Debug("Received SearchUsers request")
+	limit, err := limitQueryParam(request, defaultSearchLimit, maxSearchLimit)
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid limit")
+		problem.WriteError(writer, err)
+		return
+	}
+	offset, err := uintQueryParam(request, "offset", 0, 0)
+	if err != nil {
+		logging.FromContext(request.Context()).Warn(err)
+		problem.WriteError(writer, err)
+		return
+	}
+
+	var viewerId uuid.UUID
+	if claims, ok := auth.ClaimsFromContext(request.Context()); ok {
+		viewerId = uuid.FromStringOrNil(claims.Subject)
+	}
+
+	search, err := h.Service.SearchUsers(request.Context(), viewerId, request.URL.Query().Get("q"), limit, offset)
+	if err != nil {
+		logging.FromContext(request.Context()).Warn(err)
+		problem.WriteError(writer, err)
+		return
+	}
+
+	res, _ := json.Marshal(search)
+
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(res)
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"game-project/internal/application"
+	"game-project/internal/application/query"
+)
+
+func TestUserHandler_SearchUsers(t *testing.T) {
+	cases := []struct {
+		fakeServiceImpl *fakeServiceImpl
+		path            string
+		expectedResult  *query.UserSearch
+		expectedStatus  int
+	}{
+		{
+			fakeServiceImpl: &fakeServiceImpl{userSearchResult: &query.UserSearch{Query: "ja", Limit: 20, Users: []*query.User{{Name: "Jake"}}}},
+			path:            "/user/search?q=ja",
+			expectedResult:  &query.UserSearch{Query: "ja", Limit: 20, Users: []*query.User{{Name: "Jake"}}},
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{err: application.ErrEmptySearch},
+			path:            "/user/search",
+			expectedStatus:  http.StatusBadRequest,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			path:            "/user/search?q=ja&limit=500",
+			expectedStatus:  http.StatusBadRequest,
+		},
+	}
+	for _, tc := range cases {
+		var response *query.UserSearch
+		handler := &UserHandler{Service: tc.fakeServiceImpl}
+		r, _ := http.NewRequest("GET", tc.path, nil)
+		w := httptest.NewRecorder()
+		router(handler).ServeHTTP(w, r)
+
+		decodeResult(w, &response)
+		if w.Code != tc.expectedStatus {
+			t.Fatalf("wrong status retrieved, should be %d and received %d instead", tc.expectedStatus, w.Code)
+		}
+		if !reflect.DeepEqual(tc.expectedResult, response) {
+			t.Fatalf("body response and expected result does not match. expected %+v and received %+v", tc.expectedResult, response)
+		}
+	}
+}
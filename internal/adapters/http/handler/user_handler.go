@@ -2,19 +2,36 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gofrs/uuid"
 	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
 
+	"game-project/internal/adapters/http/auth"
+	"game-project/internal/adapters/http/problem"
 	"game-project/internal/application"
 	"game-project/internal/application/command"
 	"game-project/internal/domain"
+	"game-project/internal/logging"
 )
 
-func NewUserHandler(repository domain.UserRepository) UserHandler {
-	return UserHandler{Service: application.NewUserService(repository)}
+const (
+	defaultLeaderboardLimit  = 50
+	maxLeaderboardLimit      = 100
+	defaultLeaderboardRadius = 5
+	maxLeaderboardRadius     = 50
+	defaultSessionsLimit     = 20
+	maxSessionsLimit         = 100
+	defaultUsersLimit        = 50
+	maxUsersLimit            = 100
+)
+
+func NewUserHandler(service application.UserService) UserHandler {
+	return UserHandler{Service: service}
 }
 
 type UserHandler struct {
@@ -22,28 +39,59 @@ type UserHandler struct {
 }
 
 func (h UserHandler) List(writer http.ResponseWriter, request *http.Request) {
-	log.Info("Received List all users request")
-	query := h.Service.ListUser()
+	logging.FromContext(request.Context()).Debug("Received List users request")
+	limit, err := limitQueryParam(request, defaultUsersLimit, maxUsersLimit)
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid limit")
+		problem.WriteError(writer, err)
+		return
+	}
+	sort, err := domain.ParseUserSort(request.URL.Query().Get("sort"))
+	if err != nil {
+		logging.FromContext(request.Context()).Warn(err)
+		problem.WriteError(writer, err)
+		return
+	}
+	// The filters are compared to int4 columns, larger values cannot match.
+	minScore, err := uintQueryParam(request, "minScore", 0, math.MaxInt32)
+	if err != nil {
+		logging.FromContext(request.Context()).Warn(err)
+		problem.WriteError(writer, err)
+		return
+	}
+	minGamesPlayed, err := uintQueryParam(request, "minGamesPlayed", 0, math.MaxInt32)
+	if err != nil {
+		logging.FromContext(request.Context()).Warn(err)
+		problem.WriteError(writer, err)
+		return
+	}
 
-	res, _ := json.Marshal(query)
+	filter := domain.UserFilter{MinScore: int64(minScore), MinGamesPlayed: int64(minGamesPlayed)}
+	users, err := h.Service.ListUser(request.Context(), sort, filter, request.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+
+	res, _ := json.Marshal(users)
 
 	writer.WriteHeader(http.StatusOK)
 	writer.Write(res)
 }
 
 func (h UserHandler) Create(writer http.ResponseWriter, request *http.Request) {
-	log.Info("Received Create user request")
+	logging.FromContext(request.Context()).Debug("Received Create user request")
 	var command command.CreateUser
 
 	err := json.NewDecoder(request.Body).Decode(&command)
 	if err != nil {
-		writer.WriteHeader(http.StatusBadRequest)
+		problem.Invalid(writer, "body", "must be a valid JSON document")
 		return
 	}
 
-	u, err := h.Service.CreateUser(command)
+	u, err := h.Service.CreateUser(request.Context(), command)
 	if err != nil {
-		writer.WriteHeader(http.StatusInternalServerError)
+		problem.WriteError(writer, err)
 		return
 	}
 
@@ -56,21 +104,21 @@ func (h UserHandler) Create(writer http.ResponseWriter, request *http.Request) {
 func (h UserHandler) UpdateUserState(writer http.ResponseWriter, request *http.Request) {
 	var command command.UpdateUserState
 	vars := mux.Vars(request)
-	log.Infof("Received UpdateUserState request for user id: %s", vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received UpdateUserState request")
 	id, err := uuid.FromString(vars["userId"])
 	if err != nil {
-		writer.WriteHeader(http.StatusBadRequest)
+		problem.Invalid(writer, "userId", "must be a UUID")
 		return
 	}
 	err = json.NewDecoder(request.Body).Decode(&command)
 	if err != nil {
-		writer.WriteHeader(http.StatusBadRequest)
+		problem.Invalid(writer, "body", "must be a valid JSON document")
 		return
 	}
 
-	err = h.Service.UpdateUserState(id, command)
+	err = h.Service.UpdateUserState(request.Context(), id, command)
 	if err != nil {
-		writer.WriteHeader(http.StatusInternalServerError)
+		problem.WriteError(writer, err)
 		return
 	}
 
@@ -80,16 +128,16 @@ func (h UserHandler) UpdateUserState(writer http.ResponseWriter, request *http.R
 func (h UserHandler) LoadUserState(writer http.ResponseWriter, request *http.Request) {
 	vars := mux.Vars(request)
 	id, err := uuid.FromString(vars["userId"])
-	log.Infof("Received LoadUserState request for user id: %s", vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received LoadUserState request")
 	if err != nil {
-		log.Warn("Request with invalid UUID")
-		writer.WriteHeader(http.StatusBadRequest)
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
 		return
 	}
 
-	state, err := h.Service.LoadUserState(id)
+	state, err := h.Service.LoadUserState(request.Context(), id)
 	if err != nil {
-		writer.WriteHeader(http.StatusNotFound)
+		problem.WriteError(writer, err)
 		return
 	}
 
@@ -99,46 +147,129 @@ func (h UserHandler) LoadUserState(writer http.ResponseWriter, request *http.Req
 	writer.Write(res)
 }
 
+func (h UserHandler) ListSessions(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, err := uuid.FromString(vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received ListSessions request")
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
+		return
+	}
+	limit, err := limitQueryParam(request, defaultSessionsLimit, maxSessionsLimit)
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid limit")
+		problem.WriteError(writer, err)
+		return
+	}
+
+	sessions, err := h.Service.ListSessions(request.Context(), id, request.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+	res, _ := json.Marshal(sessions)
+
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(res)
+}
+
 func (h UserHandler) UpdateUserFriends(writer http.ResponseWriter, request *http.Request) {
 	var command command.UpdateUserFriends
 	vars := mux.Vars(request)
 	id, err := uuid.FromString(vars["userId"])
-	log.Infof("Received UpdateUserFriends request for user id: %s", vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received UpdateUserFriends request")
 	if err != nil {
-		log.Warn("Request with invalid UUID")
-		writer.WriteHeader(http.StatusBadRequest)
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
 		return
 	}
 	err = json.NewDecoder(request.Body).Decode(&command)
 	if err != nil {
-		writer.WriteHeader(http.StatusBadRequest)
+		problem.Invalid(writer, "body", "must be a valid JSON document")
 		return
 	}
 
-	newFriends, err := h.Service.UpdateUserFriends(id, command)
+	updated, err := h.Service.UpdateUserFriends(request.Context(), id, command)
 	if err != nil {
-		log.Warn("error inserting friends")
-		writer.WriteHeader(http.StatusNotFound)
+		logging.FromContext(request.Context()).Warn("error replacing friends")
+		problem.WriteError(writer, err)
 		return
 	}
-	log.Infof("%d new friends created", newFriends)
+	logging.FromContext(request.Context()).WithFields(log.Fields{"added": updated.Added, "removed": updated.Removed}).Info("Friends updated")
+	res, _ := json.Marshal(updated)
 
-	writer.WriteHeader(http.StatusCreated)
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(res)
+}
+
+func (h UserHandler) PatchUserFriends(writer http.ResponseWriter, request *http.Request) {
+	var command command.UpdateUserFriends
+	vars := mux.Vars(request)
+	id, err := uuid.FromString(vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received PatchUserFriends request")
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
+		return
+	}
+	err = json.NewDecoder(request.Body).Decode(&command)
+	if err != nil {
+		problem.Invalid(writer, "body", "must be a valid JSON document")
+		return
+	}
+
+	updated, err := h.Service.PatchUserFriends(request.Context(), id, command)
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("error updating friends")
+		problem.WriteError(writer, err)
+		return
+	}
+	logging.FromContext(request.Context()).WithFields(log.Fields{"added": updated.Added, "removed": updated.Removed}).Info("Friends updated")
+	res, _ := json.Marshal(updated)
+
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(res)
+}
+
+func (h UserHandler) RemoveUserFriend(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	logging.FromContext(request.Context()).Debugf("Received RemoveUserFriend request for friend id: %s", vars["friendId"])
+	id, err := uuid.FromString(vars["userId"])
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
+		return
+	}
+	friendId, err := uuid.FromString(vars["friendId"])
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid friend UUID")
+		problem.Invalid(writer, "friendId", "must be a UUID")
+		return
+	}
+
+	err = h.Service.RemoveUserFriend(request.Context(), id, friendId)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
 }
 
 func (h UserHandler) ListUserFriends(writer http.ResponseWriter, request *http.Request) {
 	vars := mux.Vars(request)
 	id, err := uuid.FromString(vars["userId"])
-	log.Infof("Received ListUserFriends request for user id: %s", vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received ListUserFriends request")
 	if err != nil {
-		log.Warn("Request with invalid UUID")
-		writer.WriteHeader(http.StatusBadRequest)
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
 		return
 	}
 
-	userFriends, err := h.Service.ListUserFriends(id)
+	userFriends, err := h.Service.ListUserFriends(request.Context(), id)
 	if err != nil {
-		writer.WriteHeader(http.StatusNotFound)
+		problem.WriteError(writer, err)
 		return
 	}
 	res, _ := json.Marshal(userFriends)
@@ -146,3 +277,143 @@ func (h UserHandler) ListUserFriends(writer http.ResponseWriter, request *http.R
 	writer.WriteHeader(http.StatusOK)
 	writer.Write(res)
 }
+
+func (h UserHandler) FriendsLeaderboard(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, err := uuid.FromString(vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received FriendsLeaderboard request")
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
+		return
+	}
+
+	period, err := domain.ParseLeaderboardPeriod(request.URL.Query().Get("period"))
+	if err != nil {
+		logging.FromContext(request.Context()).Warn(err)
+		problem.WriteError(writer, err)
+		return
+	}
+
+	friendsLeaderboard, err := h.Service.FriendsLeaderboard(request.Context(), id, period)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+	res, _ := json.Marshal(friendsLeaderboard)
+
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(res)
+}
+
+func (h UserHandler) Leaderboard(writer http.ResponseWriter, request *http.Request) {
+	logging.FromContext(request.Context()).Debug("Received Leaderboard request")
+	limit, err := uintQueryParam(request, "limit", defaultLeaderboardLimit, maxLeaderboardLimit)
+	if err != nil {
+		logging.FromContext(request.Context()).Warn(err)
+		problem.WriteError(writer, err)
+		return
+	}
+	offset, err := uintQueryParam(request, "offset", 0, 0)
+	if err != nil {
+		logging.FromContext(request.Context()).Warn(err)
+		problem.WriteError(writer, err)
+		return
+	}
+
+	period, err := domain.ParseLeaderboardPeriod(request.URL.Query().Get("period"))
+	if err != nil {
+		logging.FromContext(request.Context()).Warn(err)
+		problem.WriteError(writer, err)
+		return
+	}
+
+	var viewerId uuid.UUID
+	if claims, ok := auth.ClaimsFromContext(request.Context()); ok {
+		viewerId = uuid.FromStringOrNil(claims.Subject)
+	}
+
+	leaderboard, err := h.Service.Leaderboard(request.Context(), viewerId, period, limit, offset)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+
+	res, _ := json.Marshal(leaderboard)
+
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(res)
+}
+
+func (h UserHandler) UserRank(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, err := uuid.FromString(vars["userId"])
+	logging.FromContext(request.Context()).Debug("Received UserRank request")
+	if err != nil {
+		logging.FromContext(request.Context()).Warn("Request with invalid UUID")
+		problem.Invalid(writer, "userId", "must be a UUID")
+		return
+	}
+	radius, err := uintQueryParam(request, "radius", defaultLeaderboardRadius, maxLeaderboardRadius)
+	if err != nil {
+		logging.FromContext(request.Context()).Warn(err)
+		problem.WriteError(writer, err)
+		return
+	}
+
+	period, err := domain.ParseLeaderboardPeriod(request.URL.Query().Get("period"))
+	if err != nil {
+		logging.FromContext(request.Context()).Warn(err)
+		problem.WriteError(writer, err)
+		return
+	}
+
+	var viewerId uuid.UUID
+	if claims, ok := auth.ClaimsFromContext(request.Context()); ok {
+		viewerId = uuid.FromStringOrNil(claims.Subject)
+	}
+
+	userRank, err := h.Service.UserRank(request.Context(), viewerId, id, period, radius)
+	if err != nil {
+		problem.WriteError(writer, err)
+		return
+	}
+	res, _ := json.Marshal(userRank)
+
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(res)
+}
+
+// uintQueryParam reads an unsigned integer query parameter, falling back to
+// def when it is absent. A max of 0 means the value is unbounded.
+func uintQueryParam(request *http.Request, key string, def uint, max uint) (uint, error) {
+	raw := request.URL.Query().Get(key)
+	if raw == "" {
+		return def, nil
+	}
+	value, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		return 0, invalidQueryParam(key, fmt.Sprintf("invalid %s query parameter: %s", key, raw), "must be a non-negative integer")
+	}
+	if max > 0 && uint(value) > max {
+		return 0, invalidQueryParam(key, fmt.Sprintf("%s query parameter must not exceed %d", key, max),
+			fmt.Sprintf("must not exceed %d", max))
+	}
+
+	return uint(value), nil
+}
+
+// limitQueryParam reads the limit query parameter of a page, which must lie
+// between 1 and max.
+func limitQueryParam(request *http.Request, def uint, max uint) (uint, error) {
+	limit, err := uintQueryParam(request, "limit", def, max)
+	if err == nil && limit == 0 {
+		return 0, invalidQueryParam("limit", "limit query parameter must be positive", "must be at least 1")
+	}
+
+	return limit, err
+}
+
+func invalidQueryParam(key string, message string, reason string) error {
+	return domain.ValidationError("invalid_parameter", message, domain.FieldError{Field: key, Message: reason})
+}
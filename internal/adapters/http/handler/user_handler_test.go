@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -12,45 +14,87 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/gorilla/mux"
 
+	"game-project/internal/adapters/http/problem"
+	"game-project/internal/application"
 	"game-project/internal/application/command"
 	"game-project/internal/application/query"
+	"game-project/internal/domain"
 )
 
 func TestUserHandler_List(t *testing.T) {
 	cases := []struct {
 		fakeServiceImpl *fakeServiceImpl
-		expectedResult []*query.User
+		path string
+		expectedResult *query.Users
 		expectedStatus int
 
 	} {
 		{
 			fakeServiceImpl: &fakeServiceImpl{
-				listResult: []*query.User{
+				listResult: &query.Users{
+					Users: []*query.User{
+						{
+							Id:   uuid.UUID{},
+							Name: "Jake",
+						},
+					},
+					NextCursor: "next",
+				},
+			},
+			path: "/user?sort=score&minScore=10&limit=1",
+			expectedResult: &query.Users{
+				Users: []*query.User{
 					{
 						Id:   uuid.UUID{},
 						Name: "Jake",
 					},
 				},
-			},
-			expectedResult: []*query.User{
-				{
-					Id:   uuid.UUID{},
-					Name: "Jake",
-				},
+				NextCursor: "next",
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			path: "/user?sort=age",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			path: "/user?limit=1000",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{listResult: &query.Users{}},
+			path: "/user?minScore=2147483647&minGamesPlayed=2147483647",
+			expectedResult: &query.Users{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			path: "/user?minScore=3000000000",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			path: "/user?minGamesPlayed=2147483648",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{err: application.ErrInvalidCursor},
+			path: "/user?cursor=garbage",
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 	for _, tc := range cases {
-		var response []*query.User
+		var response *query.Users
 		handler := &UserHandler{Service: tc.fakeServiceImpl}
-		r, _ := http.NewRequest("GET", "/user", nil)
+		r, _ := http.NewRequest("GET", tc.path, nil)
 		w := httptest.NewRecorder()
 		router(handler).ServeHTTP(w, r)
 
-		json.NewDecoder(w.Body).Decode(&response)
+		decodeResult(w, &response)
 		if w.Code != tc.expectedStatus {
-			t.Fatalf("wrong status retrieved, should be %d and received %d instead", w.Code, tc.expectedStatus)
+			t.Fatalf("wrong status retrieved, should be %d and received %d instead", tc.expectedStatus, w.Code)
 		}
 		if !reflect.DeepEqual(tc.expectedResult, response) {
 			t.Fatalf("body response and expected result does not match. expected %+v and received %+v", tc.expectedResult, response)
@@ -89,7 +133,7 @@ func TestUserHandler_Create(t *testing.T) {
 		w := httptest.NewRecorder()
 		router(handler).ServeHTTP(w, r)
 
-		json.NewDecoder(w.Body).Decode(&response)
+		decodeResult(w, &response)
 		if w.Code != tc.expectedStatus {
 			t.Fatalf("wrong status retrieved, should be %d and received %d instead", w.Code, tc.expectedStatus)
 		}
@@ -129,7 +173,7 @@ func TestUserHandler_LoadUserState(t *testing.T) {
 		w := httptest.NewRecorder()
 		router(handler).ServeHTTP(w, r)
 
-		json.NewDecoder(w.Body).Decode(&response)
+		decodeResult(w, &response)
 		if w.Code != tc.expectedStatus {
 			t.Fatalf("wrong status retrieved, should be %d and received %d instead", w.Code, tc.expectedStatus)
 		}
@@ -189,7 +233,7 @@ func TestUserHandler_ListUserFriends(t *testing.T) {
 		w := httptest.NewRecorder()
 		router(handler).ServeHTTP(w, r)
 
-		json.NewDecoder(w.Body).Decode(&response)
+		decodeResult(w, &response)
 		if w.Code != tc.expectedStatus {
 			t.Fatalf("wrong status retrieved, should be %d and received %d instead", w.Code, tc.expectedStatus)
 		}
@@ -199,47 +243,614 @@ func TestUserHandler_ListUserFriends(t *testing.T) {
 	}
 }
 
+func TestUserHandler_Leaderboard(t *testing.T) {
+	cases := []struct {
+		fakeServiceImpl *fakeServiceImpl
+		query           string
+		expectedResult  *query.Leaderboard
+		expectedStatus  int
+	}{
+		{
+			fakeServiceImpl: &fakeServiceImpl{
+				leaderboardResult: &query.Leaderboard{
+					Limit: 2,
+					Entries: []*query.LeaderboardEntry{
+						{Rank: 1, Name: "Jeferson", Score: 35},
+						{Rank: 2, Name: "Claudio", Score: 20},
+					},
+				},
+			},
+			query: "?limit=2&period=daily",
+			expectedResult: &query.Leaderboard{
+				Limit: 2,
+				Entries: []*query.LeaderboardEntry{
+					{Rank: 1, Name: "Jeferson", Score: 35},
+					{Rank: 2, Name: "Claudio", Score: 20},
+				},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			query:           "?limit=1000",
+			expectedResult:  nil,
+			expectedStatus:  http.StatusBadRequest,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			query:           "?period=yearly",
+			expectedResult:  nil,
+			expectedStatus:  http.StatusBadRequest,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			query:           "?offset=-1",
+			expectedResult:  nil,
+			expectedStatus:  http.StatusBadRequest,
+		},
+	}
+	for _, tc := range cases {
+		var response *query.Leaderboard
+		handler := &UserHandler{Service: tc.fakeServiceImpl}
+		r, _ := http.NewRequest("GET", "/leaderboard"+tc.query, nil)
+		w := httptest.NewRecorder()
+		router(handler).ServeHTTP(w, r)
+
+		decodeResult(w, &response)
+		if w.Code != tc.expectedStatus {
+			t.Fatalf("wrong status retrieved, should be %d and received %d instead", tc.expectedStatus, w.Code)
+		}
+		if !reflect.DeepEqual(tc.expectedResult, response) {
+			t.Fatalf("body response and expected result does not match. expected %+v and received %+v", tc.expectedResult, response)
+		}
+	}
+}
+
+func TestUserHandler_UserRank(t *testing.T) {
+	userId := "18dd75e9-3d4a-48e2-bafc-3c8f95a8f0d1"
+	cases := []struct {
+		fakeServiceImpl *fakeServiceImpl
+		path            string
+		expectedResult  *query.UserRank
+		expectedStatus  int
+	}{
+		{
+			fakeServiceImpl: &fakeServiceImpl{
+				userRankResult: &query.UserRank{
+					Period: "weekly",
+					User:   &query.LeaderboardEntry{Rank: 2, Name: "Claudio", Score: 20},
+					Entries: []*query.LeaderboardEntry{
+						{Rank: 1, Name: "Jeferson", Score: 35},
+						{Rank: 2, Name: "Claudio", Score: 20},
+					},
+				},
+			},
+			path: userId + "?radius=1&period=weekly",
+			expectedResult: &query.UserRank{
+				Period: "weekly",
+				User:   &query.LeaderboardEntry{Rank: 2, Name: "Claudio", Score: 20},
+				Entries: []*query.LeaderboardEntry{
+					{Rank: 1, Name: "Jeferson", Score: 35},
+					{Rank: 2, Name: "Claudio", Score: 20},
+				},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			path:            "not-a-uuid",
+			expectedResult:  nil,
+			expectedStatus:  http.StatusBadRequest,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			path:            userId + "?radius=51",
+			expectedResult:  nil,
+			expectedStatus:  http.StatusBadRequest,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			path:            userId + "?radius=-1",
+			expectedResult:  nil,
+			expectedStatus:  http.StatusBadRequest,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			path:            userId + "?period=yearly",
+			expectedResult:  nil,
+			expectedStatus:  http.StatusBadRequest,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{err: domain.NotFoundError("user_not_found", "no user found")},
+			path:            userId,
+			expectedResult:  nil,
+			expectedStatus:  http.StatusNotFound,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{err: context.DeadlineExceeded},
+			path:            userId,
+			expectedResult:  nil,
+			expectedStatus:  http.StatusGatewayTimeout,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{err: errors.New("connection refused")},
+			path:            userId,
+			expectedResult:  nil,
+			expectedStatus:  http.StatusInternalServerError,
+		},
+	}
+	for _, tc := range cases {
+		var response *query.UserRank
+		handler := &UserHandler{Service: tc.fakeServiceImpl}
+		r, _ := http.NewRequest("GET", "/leaderboard/user/"+tc.path, nil)
+		w := httptest.NewRecorder()
+		router(handler).ServeHTTP(w, r)
+
+		decodeResult(w, &response)
+		if w.Code != tc.expectedStatus {
+			t.Fatalf("wrong status retrieved, should be %d and received %d instead", tc.expectedStatus, w.Code)
+		}
+		if !reflect.DeepEqual(tc.expectedResult, response) {
+			t.Fatalf("body response and expected result does not match. expected %+v and received %+v", tc.expectedResult, response)
+		}
+	}
+}
+
+func TestUserHandler_FriendsLeaderboard(t *testing.T) {
+	userId := "18dd75e9-3d4a-48e2-bafc-3c8f95a8f0d1"
+	friendId := uuid.FromStringOrNil("f2b1c0a4-9a53-4f1e-8d0c-6a5b0d2b7e11")
+	cases := []struct {
+		fakeServiceImpl *fakeServiceImpl
+		path            string
+		expectedResult  *query.FriendsLeaderboard
+		expectedStatus  int
+	}{
+		{
+			fakeServiceImpl: &fakeServiceImpl{
+				friendsLeaderboardResult: &query.FriendsLeaderboard{
+					Period: "daily",
+					Friends: []*query.FriendRank{
+						{Friend: query.Friend{Id: friendId, Name: "Jeferson", Highscore: 35}, Rank: 1, Delta: 15},
+					},
+				},
+			},
+			path: userId + "/friends/leaderboard?period=daily",
+			expectedResult: &query.FriendsLeaderboard{
+				Period: "daily",
+				Friends: []*query.FriendRank{
+					{Friend: query.Friend{Id: friendId, Name: "Jeferson", Highscore: 35}, Rank: 1, Delta: 15},
+				},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			path:            "not-a-uuid/friends/leaderboard",
+			expectedResult:  nil,
+			expectedStatus:  http.StatusBadRequest,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			path:            userId + "/friends/leaderboard?period=yearly",
+			expectedResult:  nil,
+			expectedStatus:  http.StatusBadRequest,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{err: domain.NotFoundError("user_not_found", "no user found")},
+			path:            userId + "/friends/leaderboard",
+			expectedResult:  nil,
+			expectedStatus:  http.StatusNotFound,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{err: context.DeadlineExceeded},
+			path:            userId + "/friends/leaderboard",
+			expectedResult:  nil,
+			expectedStatus:  http.StatusGatewayTimeout,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{err: errors.New("connection refused")},
+			path:            userId + "/friends/leaderboard",
+			expectedResult:  nil,
+			expectedStatus:  http.StatusInternalServerError,
+		},
+	}
+	for _, tc := range cases {
+		var response *query.FriendsLeaderboard
+		handler := &UserHandler{Service: tc.fakeServiceImpl}
+		r, _ := http.NewRequest("GET", "/user/"+tc.path, nil)
+		w := httptest.NewRecorder()
+		router(handler).ServeHTTP(w, r)
+
+		decodeResult(w, &response)
+		if w.Code != tc.expectedStatus {
+			t.Fatalf("wrong status retrieved, should be %d and received %d instead", tc.expectedStatus, w.Code)
+		}
+		if !reflect.DeepEqual(tc.expectedResult, response) {
+			t.Fatalf("body response and expected result does not match. expected %+v and received %+v", tc.expectedResult, response)
+		}
+	}
+}
+
+func TestUserHandler_ListSessions(t *testing.T) {
+	cases := []struct {
+		fakeServiceImpl *fakeServiceImpl
+		query           string
+		expectedResult  *query.GameSessions
+		expectedStatus  int
+	}{
+		{
+			fakeServiceImpl: &fakeServiceImpl{
+				listSessionsResult: &query.GameSessions{
+					Sessions:   []*query.GameSession{{Id: 2, Score: 300, DurationMs: 1500}},
+					NextCursor: "next",
+				},
+			},
+			query: "?limit=1",
+			expectedResult: &query.GameSessions{
+				Sessions:   []*query.GameSession{{Id: 2, Score: 300, DurationMs: 1500}},
+				NextCursor: "next",
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{err: application.ErrInvalidCursor},
+			query:           "?cursor=bogus",
+			expectedResult:  nil,
+			expectedStatus:  http.StatusBadRequest,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			query:           "?limit=0",
+			expectedResult:  nil,
+			expectedStatus:  http.StatusBadRequest,
+		},
+	}
+	for _, tc := range cases {
+		var response *query.GameSessions
+		handler := &UserHandler{Service: tc.fakeServiceImpl}
+		id, _ := uuid.NewV4()
+		r, _ := http.NewRequest("GET", fmt.Sprintf("/user/%s/sessions%s", id, tc.query), nil)
+		w := httptest.NewRecorder()
+		router(handler).ServeHTTP(w, r)
+
+		decodeResult(w, &response)
+		if w.Code != tc.expectedStatus {
+			t.Fatalf("wrong status retrieved, should be %d and received %d instead", tc.expectedStatus, w.Code)
+		}
+		if !reflect.DeepEqual(tc.expectedResult, response) {
+			t.Fatalf("body response and expected result does not match. expected %+v and received %+v", tc.expectedResult, response)
+		}
+	}
+}
+
+func TestUserHandler_UpdateUserFriends(t *testing.T) {
+	cases := []struct {
+		fakeServiceImpl *fakeServiceImpl
+		method          string
+		body            string
+		expectedResult  *query.FriendsUpdated
+		expectedStatus  int
+	}{
+		{
+			fakeServiceImpl: &fakeServiceImpl{friendsUpdatedResult: &query.FriendsUpdated{Added: 2, Removed: 1}},
+			method:          "PUT",
+			body:            `{"friends": ["d6c55aa8-f081-4fa9-955f-2e4f60139169", "8a5bf865-82f2-4aaa-9bc2-ebd68fb967a0"]}`,
+			expectedResult:  &query.FriendsUpdated{Added: 2, Removed: 1},
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{friendsUpdatedResult: &query.FriendsUpdated{Added: 0, Removed: 1}},
+			method:          "PATCH",
+			body:            `{"remove": ["d6c55aa8-f081-4fa9-955f-2e4f60139169"]}`,
+			expectedResult:  &query.FriendsUpdated{Added: 0, Removed: 1},
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			fakeServiceImpl: &fakeServiceImpl{},
+			method:          "PATCH",
+			body:            `{"add": ["not-a-uuid"]}`,
+			expectedResult:  nil,
+			expectedStatus:  http.StatusBadRequest,
+		},
+	}
+	for _, tc := range cases {
+		var response *query.FriendsUpdated
+		handler := &UserHandler{Service: tc.fakeServiceImpl}
+		id, _ := uuid.NewV4()
+		r, _ := http.NewRequest(tc.method, fmt.Sprintf("/user/%s/friends", id), strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		router(handler).ServeHTTP(w, r)
+
+		decodeResult(w, &response)
+		if w.Code != tc.expectedStatus {
+			t.Fatalf("wrong status retrieved, should be %d and received %d instead", tc.expectedStatus, w.Code)
+		}
+		if !reflect.DeepEqual(tc.expectedResult, response) {
+			t.Fatalf("body response and expected result does not match. expected %+v and received %+v", tc.expectedResult, response)
+		}
+	}
+}
+
+func TestUserHandler_Cancellation(t *testing.T) {
+	userId, _ := uuid.NewV4()
+	handler := &UserHandler{Service: &fakeServiceImpl{loadUserStateResult: &query.UserGameStateQuery{}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r, _ := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("/user/%s/state", userId), nil)
+	w := httptest.NewRecorder()
+	router(handler).ServeHTTP(w, r)
+	if w.Code != problem.StatusClientClosedRequest {
+		t.Fatalf("wrong status retrieved, should be %d and received %d instead", problem.StatusClientClosedRequest, w.Code)
+	}
+
+	handler = &UserHandler{Service: &fakeServiceImpl{err: context.DeadlineExceeded}}
+	r, _ = http.NewRequest("GET", "/leaderboard", nil)
+	w = httptest.NewRecorder()
+	router(handler).ServeHTTP(w, r)
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("wrong status retrieved, should be %d and received %d instead", http.StatusGatewayTimeout, w.Code)
+	}
+}
+
+func TestUserHandler_Problems(t *testing.T) {
+	userId, _ := uuid.NewV4()
+	cases := []struct {
+		name            string
+		fakeServiceImpl *fakeServiceImpl
+		method          string
+		path            string
+		body            string
+		expected        problem.Details
+	}{
+		{
+			name:            "invalid user id",
+			fakeServiceImpl: &fakeServiceImpl{},
+			method:          "GET",
+			path:            "/user/not-a-uuid/state",
+			expected: problem.Details{Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest,
+				Detail: "invalid userId", Code: "invalid_parameter",
+				Fields: []domain.FieldError{{Field: "userId", Message: "must be a UUID"}}},
+		},
+		{
+			name:            "limit out of range",
+			fakeServiceImpl: &fakeServiceImpl{},
+			method:          "GET",
+			path:            "/user?limit=0",
+			expected: problem.Details{Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest,
+				Detail: "limit query parameter must be positive", Code: "invalid_parameter",
+				Fields: []domain.FieldError{{Field: "limit", Message: "must be at least 1"}}},
+		},
+		{
+			name:            "unknown period",
+			fakeServiceImpl: &fakeServiceImpl{},
+			method:          "GET",
+			path:            "/leaderboard?period=yearly",
+			expected: problem.Details{Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest,
+				Detail: "unknown leaderboard period: yearly", Code: "invalid_period",
+				Fields: []domain.FieldError{{Field: "period", Message: "must be one of daily, weekly, monthly or all"}}},
+		},
+		{
+			name:            "taken name",
+			fakeServiceImpl: &fakeServiceImpl{err: domain.ConflictError("name_taken", "the name is already taken")},
+			method:          "POST",
+			path:            "/user",
+			body:            `{"name": "Paul"}`,
+			expected: problem.Details{Type: "about:blank", Title: "Conflict", Status: http.StatusConflict,
+				Detail: "the name is already taken", Code: "name_taken"},
+		},
+		{
+			name:            "blocked user",
+			fakeServiceImpl: &fakeServiceImpl{err: application.ErrUserBlocked},
+			method:          "POST",
+			path:            fmt.Sprintf("/user/%s/friend-requests", userId),
+			body:            fmt.Sprintf(`{"friendId": "%s"}`, uuid.Must(uuid.NewV4())),
+			expected: problem.Details{Type: "about:blank", Title: "Forbidden", Status: http.StatusForbidden,
+				Detail: application.ErrUserBlocked.Error(), Code: "user_blocked"},
+		},
+		{
+			name:            "database unavailable",
+			fakeServiceImpl: &fakeServiceImpl{err: domain.UnavailableError("database_unavailable", "the database is unavailable")},
+			method:          "GET",
+			path:            fmt.Sprintf("/user/%s/state", userId),
+			expected: problem.Details{Type: "about:blank", Title: "Service Unavailable", Status: http.StatusServiceUnavailable,
+				Detail: "the database is unavailable", Code: "database_unavailable"},
+		},
+		{
+			name:            "unexpected error",
+			fakeServiceImpl: &fakeServiceImpl{err: errors.New("connection refused")},
+			method:          "GET",
+			path:            fmt.Sprintf("/user/%s/friends", userId),
+			expected: problem.Details{Type: "about:blank", Title: "Internal Server Error", Status: http.StatusInternalServerError,
+				Detail: "an unexpected error occurred", Code: "internal"},
+		},
+	}
+	for _, tc := range cases {
+		handler := &UserHandler{Service: tc.fakeServiceImpl}
+		r, _ := http.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		router(handler).ServeHTTP(w, r)
+
+		if w.Code != tc.expected.Status {
+			t.Fatalf("%s: wrong status retrieved, should be %d and received %d instead", tc.name, tc.expected.Status, w.Code)
+		}
+		if contentType := w.Header().Get("Content-Type"); contentType != problem.ContentType {
+			t.Fatalf("%s: wrong content type, should be %s and received %s instead", tc.name, problem.ContentType, contentType)
+		}
+		var response problem.Details
+		json.NewDecoder(w.Body).Decode(&response)
+		if !reflect.DeepEqual(tc.expected, response) {
+			t.Fatalf("%s: problem and expected result does not match. expected %+v and received %+v", tc.name, tc.expected, response)
+		}
+	}
+}
+
 type fakeServiceImpl struct {
-	listResult []*query.User
+	listResult *query.Users
 	createUserResult *query.User
 	loadUserStateResult *query.UserGameStateQuery
-	nUserFriendsUpdated int64
+	friendsUpdatedResult *query.FriendsUpdated
 	listUserFriendsResult *query.UserFriends
+	leaderboardResult *query.Leaderboard
+	userRankResult *query.UserRank
+	friendsLeaderboardResult *query.FriendsLeaderboard
+	listSessionsResult *query.GameSessions
+	friendRequestResult *query.FriendRequest
+	friendRequestsResult *query.FriendRequests
+	blocksResult *query.Blocks
+	userProfileResult *query.UserProfile
+	userSearchResult *query.UserSearch
+	userSecretResult *query.UserSecret
 	err error
 }
 
-func (f fakeServiceImpl) ListUser() []*query.User {
-	return f.listResult
+func (f fakeServiceImpl) ListUser(ctx context.Context, sort domain.UserSort, filter domain.UserFilter, cursor string, limit uint) (*query.Users, error) {
+	return f.listResult, f.errFor(ctx)
 }
 
-func (f fakeServiceImpl) CreateUser(user command.CreateUser) (*query.User, error) {
-	return f.createUserResult, f.err
+func (f fakeServiceImpl) CreateUser(ctx context.Context, user command.CreateUser) (*query.User, error) {
+	return f.createUserResult, f.errFor(ctx)
 }
 
-func (f fakeServiceImpl) UpdateUserState(userId uuid.UUID, command command.UpdateUserState) error {
-	return f.err
+func (f fakeServiceImpl) UpdateUserState(ctx context.Context, userId uuid.UUID, command command.UpdateUserState) error {
+	return f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) LoadUserState(ctx context.Context, userId uuid.UUID) (*query.UserGameStateQuery, error) {
+	return f.loadUserStateResult, f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) SearchUsers(ctx context.Context, viewerId uuid.UUID, term string, limit uint, offset uint) (*query.UserSearch, error) {
+	return f.userSearchResult, f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) GetUserProfile(ctx context.Context, userId uuid.UUID) (*query.UserProfile, error) {
+	return f.userProfileResult, f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) UpdateUserProfile(ctx context.Context, userId uuid.UUID, command command.UpdateUserProfile) (*query.UserProfile, error) {
+	return f.userProfileResult, f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) DeleteUser(ctx context.Context, userId uuid.UUID) error {
+	return f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) UpdateUserFriends(ctx context.Context, userId uuid.UUID, command command.UpdateUserFriends) (*query.FriendsUpdated, error) {
+	return f.friendsUpdatedResult, f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) PatchUserFriends(ctx context.Context, userId uuid.UUID, command command.UpdateUserFriends) (*query.FriendsUpdated, error) {
+	return f.friendsUpdatedResult, f.errFor(ctx)
 }
 
-func (f fakeServiceImpl) LoadUserState(userId uuid.UUID) (*query.UserGameStateQuery, error) {
-	return f.loadUserStateResult, f.err
+func (f fakeServiceImpl) RemoveUserFriend(ctx context.Context, userId uuid.UUID, friendId uuid.UUID) error {
+	return f.errFor(ctx)
 }
 
-func (f fakeServiceImpl) UpdateUserFriends(userId uuid.UUID, command command.UpdateUserFriends) (int64, error) {
-	return f.nUserFriendsUpdated, f.err
+func (f fakeServiceImpl) ListUserFriends(ctx context.Context, userId uuid.UUID) (*query.UserFriends, error) {
+	return f.listUserFriendsResult, f.errFor(ctx)
 }
 
-func (f fakeServiceImpl) ListUserFriends(userId uuid.UUID) (*query.UserFriends, error) {
-	return f.listUserFriendsResult, f.err
+func (f fakeServiceImpl) Leaderboard(ctx context.Context, viewerId uuid.UUID, period domain.LeaderboardPeriod, limit uint, offset uint) (*query.Leaderboard, error) {
+	return f.leaderboardResult, f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) UserRank(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID, period domain.LeaderboardPeriod, radius uint) (*query.UserRank, error) {
+	return f.userRankResult, f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) FriendsLeaderboard(ctx context.Context, userId uuid.UUID, period domain.LeaderboardPeriod) (*query.FriendsLeaderboard, error) {
+	return f.friendsLeaderboardResult, f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) ListSessions(ctx context.Context, userId uuid.UUID, cursor string, limit uint) (*query.GameSessions, error) {
+	return f.listSessionsResult, f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) Authenticate(ctx context.Context, command command.IssueToken) error {
+	return f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) ResetUserSecret(ctx context.Context, userId uuid.UUID) (*query.UserSecret, error) {
+	return f.userSecretResult, f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) SendFriendRequest(ctx context.Context, userId uuid.UUID, command command.SendFriendRequest) (*query.FriendRequest, error) {
+	return f.friendRequestResult, f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) ListFriendRequests(ctx context.Context, userId uuid.UUID, direction domain.FriendRequestDirection) (*query.FriendRequests, error) {
+	return f.friendRequestsResult, f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) AcceptFriendRequest(ctx context.Context, userId uuid.UUID, requestId uuid.UUID) error {
+	return f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) DeclineFriendRequest(ctx context.Context, userId uuid.UUID, requestId uuid.UUID) error {
+	return f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) CancelFriendRequest(ctx context.Context, userId uuid.UUID, requestId uuid.UUID) error {
+	return f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) BlockUser(ctx context.Context, userId uuid.UUID, command command.BlockUser) error {
+	return f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) UnblockUser(ctx context.Context, userId uuid.UUID, blockedId uuid.UUID) error {
+	return f.errFor(ctx)
+}
+
+func (f fakeServiceImpl) ListBlocks(ctx context.Context, userId uuid.UUID) (*query.Blocks, error) {
+	return f.blocksResult, f.errFor(ctx)
+}
+
+// errFor fails like the real service once the request context is done.
+func (f fakeServiceImpl) errFor(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.err
+}
+
+// decodeResult decodes the body of successful responses into result, and
+// leaves it untouched for problem responses.
+func decodeResult(w *httptest.ResponseRecorder, result interface{}) {
+	if w.Header().Get("Content-Type") == problem.ContentType {
+		return
+	}
+	json.NewDecoder(w.Body).Decode(result)
 }
 
 func router(handler *UserHandler) *mux.Router {
 	r := mux.NewRouter()
 	r.HandleFunc("/user", handler.List).Methods("GET")
 	r.HandleFunc("/user", handler.Create).Methods("POST")
+	r.HandleFunc("/user/search", handler.SearchUsers).Methods("GET")
+	r.HandleFunc("/user/{userId}", handler.GetUser).Methods("GET")
+	r.HandleFunc("/user/{userId}", handler.UpdateUserProfile).Methods("PATCH")
+	r.HandleFunc("/user/{userId}", handler.DeleteUser).Methods("DELETE")
 	r.HandleFunc("/user/{userId}/state", handler.UpdateUserState).Methods("PUT")
 	r.HandleFunc("/user/{userId}/state", handler.LoadUserState).Methods("GET")
+	r.HandleFunc("/user/{userId}/sessions", handler.ListSessions).Methods("GET")
 	r.HandleFunc("/user/{userId}/friends", handler.UpdateUserFriends).Methods("PUT")
+	r.HandleFunc("/user/{userId}/friends", handler.PatchUserFriends).Methods("PATCH")
+	r.HandleFunc("/user/{userId}/friends/{friendId}", handler.RemoveUserFriend).Methods("DELETE")
 	r.HandleFunc("/user/{userId}/friends", handler.ListUserFriends).Methods("GET")
+	r.HandleFunc("/user/{userId}/friends/leaderboard", handler.FriendsLeaderboard).Methods("GET")
+	r.HandleFunc("/user/{userId}/friend-requests", handler.SendFriendRequest).Methods("POST")
+	r.HandleFunc("/user/{userId}/friend-requests", handler.ListFriendRequests).Methods("GET")
+	r.HandleFunc("/user/{userId}/friend-requests/{requestId}/accept", handler.AcceptFriendRequest).Methods("POST")
+	r.HandleFunc("/user/{userId}/friend-requests/{requestId}/decline", handler.DeclineFriendRequest).Methods("POST")
+	r.HandleFunc("/user/{userId}/friend-requests/{requestId}", handler.CancelFriendRequest).Methods("DELETE")
+	r.HandleFunc("/user/{userId}/blocks", handler.BlockUser).Methods("POST")
+	r.HandleFunc("/user/{userId}/blocks", handler.ListBlocks).Methods("GET")
+	r.HandleFunc("/user/{userId}/blocks/{blockedId}", handler.UnblockUser).Methods("DELETE")
+	r.HandleFunc("/leaderboard", handler.Leaderboard).Methods("GET")
+	r.HandleFunc("/leaderboard/user/{userId}", handler.UserRank).Methods("GET")
 
 	return r
 }
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"game-project/internal/logging"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	// maxRequestIDLength bounds the ids accepted from callers, longer ones
+	// are replaced rather than logged.
+	maxRequestIDLength = 128
+)
+
+// RequestID propagates the X-Request-ID of the request, or assigns a new one,
+// echoes it in the response and puts a logger bearing it into the context.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requestId := request.Header.Get(RequestIDHeader)
+		if !validRequestID(requestId) {
+			requestId = uuid.Must(uuid.NewV4()).String()
+		}
+		writer.Header().Set(RequestIDHeader, requestId)
+
+		ctx := logging.WithFields(request.Context(), log.Fields{
+			"request_id": requestId,
+			"method":     request.Method,
+			"path":       request.URL.Path,
+		})
+		next.ServeHTTP(writer, request.WithContext(ctx))
+	})
+}
+
+// validRequestID accepts non-empty ids of printable ASCII characters.
+func validRequestID(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestId); i++ {
+		if requestId[i] < ' ' || requestId[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// AccessLog adds the route template and the {userId} of the request to the
+// logger of the context, and logs the status and latency of every request.
+// It must be used as a mux middleware, which only runs once a route matched.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		fields := log.Fields{}
+		if current := mux.CurrentRoute(request); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				fields["route"] = template
+			}
+		}
+		if userId, ok := mux.Vars(request)["userId"]; ok {
+			fields["user_id"] = userId
+		}
+		ctx := logging.WithFields(request.Context(), fields)
+		recorder := NewStatusRecorder(writer)
+		start := time.Now()
+
+		next.ServeHTTP(recorder, request.WithContext(ctx))
+
+		logger := logging.FromContext(ctx).WithFields(log.Fields{
+			"status":     recorder.Status,
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+		})
+		switch {
+		case recorder.Status >= http.StatusInternalServerError:
+			logger.Error("Request failed")
+		case recorder.Status >= http.StatusBadRequest:
+			logger.Warn("Request rejected")
+		default:
+			logger.Info("Request served")
+		}
+	})
+}
@@ -0,0 +1,53 @@
+// Package middleware holds the HTTP middlewares that wrap the whole router,
+// so that they also apply to requests no route matches, along with AccessLog
+// which needs the matched route.
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"game-project/internal/adapters/http/problem"
+	"game-project/internal/logging"
+)
+
+// Recover turns a panicking handler into a 500 response instead of a dropped
+// connection, and logs the panic with its stack trace.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+			if err == http.ErrAbortHandler {
+				// Aborting on purpose, let net/http close the connection quietly.
+				panic(err)
+			}
+			logging.FromContext(request.Context()).Errorf("Recovered from a panic serving %s %s: %v\n%s", request.Method, request.URL.Path, err, debug.Stack())
+			problem.Write(writer, http.StatusInternalServerError, "internal", "an unexpected error occurred")
+		}()
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// LimitBody rejects request bodies larger than maxBytes. Requests announcing
+// a larger Content-Length are answered with 413 right away, others fail to
+// read past the limit.
+func LimitBody(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if request.ContentLength > maxBytes {
+				logging.FromContext(request.Context()).Warnf("Request body of %d bytes exceeds the limit of %d", request.ContentLength, maxBytes)
+				problem.Write(writer, http.StatusRequestEntityTooLarge, "body_too_large",
+					fmt.Sprintf("the request body must not exceed %d bytes", maxBytes))
+				return
+			}
+			request.Body = http.MaxBytesReader(writer, request.Body, maxBytes)
+
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"game-project/internal/logging"
+)
+
+func TestRecover(t *testing.T) {
+	panicking := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		panic("boom")
+	})
+	r, _ := http.NewRequest("GET", "/user", nil)
+	w := httptest.NewRecorder()
+	Recover(panicking).ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("wrong status retrieved, should be %d and received %d instead", http.StatusInternalServerError, w.Code)
+	}
+}
+
+func TestLimitBody(t *testing.T) {
+	reading := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if _, err := ioutil.ReadAll(request.Body); err != nil {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+	})
+	cases := []struct {
+		name           string
+		body           string
+		unknownLength  bool
+		expectedStatus int
+	}{
+		{name: "within the limit", body: "0123456789", expectedStatus: http.StatusOK},
+		{name: "announced too large", body: "0123456789a", expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "streamed too large", body: "0123456789a", unknownLength: true, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		r, _ := http.NewRequest("POST", "/user", strings.NewReader(tc.body))
+		if tc.unknownLength {
+			r.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		LimitBody(10)(reading).ServeHTTP(w, r)
+
+		if w.Code != tc.expectedStatus {
+			t.Errorf("%s: wrong status retrieved, should be %d and received %d instead", tc.name, tc.expectedStatus, w.Code)
+		}
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	cases := []struct {
+		name       string
+		requestId  string
+		propagated bool
+	}{
+		{name: "propagated", requestId: "abc-123", propagated: true},
+		{name: "generated when missing", requestId: ""},
+		{name: "replaced when too long", requestId: strings.Repeat("a", maxRequestIDLength+1)},
+		{name: "replaced when not printable", requestId: "abc\x01"},
+	}
+
+	for _, tc := range cases {
+		var logged interface{}
+		handler := RequestID(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			logged = logging.FromContext(request.Context()).Data["request_id"]
+		}))
+		r, _ := http.NewRequest("GET", "/user", nil)
+		if tc.requestId != "" {
+			r.Header.Set(RequestIDHeader, tc.requestId)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		responded := w.Header().Get(RequestIDHeader)
+		if tc.propagated && responded != tc.requestId {
+			t.Errorf("%s: wrong request id responded, should be %q and received %q instead", tc.name, tc.requestId, responded)
+		}
+		if !tc.propagated && uuid.FromStringOrNil(responded) == uuid.Nil {
+			t.Errorf("%s: a new request id should have been generated, received %q instead", tc.name, responded)
+		}
+		if logged != responded {
+			t.Errorf("%s: the logger should bear the request id %q, received %v instead", tc.name, responded, logged)
+		}
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	cases := []struct {
+		name          string
+		status        int
+		expectedLevel log.Level
+	}{
+		{name: "served", status: http.StatusOK, expectedLevel: log.InfoLevel},
+		{name: "rejected", status: http.StatusNotFound, expectedLevel: log.WarnLevel},
+		{name: "failed", status: http.StatusInternalServerError, expectedLevel: log.ErrorLevel},
+	}
+
+	for _, tc := range cases {
+		logger, hook := test.NewNullLogger()
+		status := tc.status
+		r := mux.NewRouter()
+		r.Use(AccessLog)
+		r.HandleFunc("/user/{userId}", func(writer http.ResponseWriter, request *http.Request) {
+			logging.FromContext(request.Context()).Info("Serving")
+			writer.WriteHeader(status)
+		})
+		request, _ := http.NewRequest("GET", "/user/42", nil)
+		request = request.WithContext(logging.NewContext(request.Context(), log.NewEntry(logger).WithField("request_id", "abc")))
+		r.ServeHTTP(httptest.NewRecorder(), request)
+
+		entries := hook.AllEntries()
+		if len(entries) != 2 {
+			t.Fatalf("%s: wrong number of lines logged, should be 2 and received %d instead", tc.name, len(entries))
+		}
+		if entries[0].Data["user_id"] != "42" || entries[0].Data["request_id"] != "abc" {
+			t.Errorf("%s: the handler logger should bear the request fields, received %v instead", tc.name, entries[0].Data)
+		}
+		access := entries[1]
+		if access.Level != tc.expectedLevel {
+			t.Errorf("%s: wrong level logged, should be %s and received %s instead", tc.name, tc.expectedLevel, access.Level)
+		}
+		if access.Data["route"] != "/user/{userId}" || access.Data["status"] != tc.status {
+			t.Errorf("%s: wrong access log fields, received %v", tc.name, access.Data)
+		}
+		if _, ok := access.Data["latency_ms"]; !ok {
+			t.Errorf("%s: the latency should be logged", tc.name)
+		}
+	}
+}
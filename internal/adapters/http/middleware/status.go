@@ -0,0 +1,28 @@
+package middleware
+
+import "net/http"
+
+// StatusRecorder remembers the status code a handler answered with, for the
+// middlewares that report on it once the handler returned.
+type StatusRecorder struct {
+	http.ResponseWriter
+	Status      int
+	wroteHeader bool
+}
+
+func NewStatusRecorder(writer http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: writer, Status: http.StatusOK}
+}
+
+func (r *StatusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.Status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *StatusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
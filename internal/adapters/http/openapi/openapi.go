@@ -0,0 +1,121 @@
+// Package openapi serves the OpenAPI 3 document describing the API, and
+// validates requests against it.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/adapters/http/problem"
+	"game-project/internal/domain"
+	"game-project/internal/logging"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+func init() {
+	// Ids are parsed the way the handlers parse them.
+	openapi3.DefineStringFormatCallback("uuid", func(value string) error {
+		_, err := uuid.FromString(value)
+		return err
+	})
+}
+
+// Handler serves the document.
+func Handler(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(spec)
+}
+
+// Load parses and checks the document.
+func Load() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Validator checks the parameters and bodies of the requests against the
+// document. Authentication is left to the auth middlewares.
+type Validator struct {
+	router routers.Router
+}
+
+func NewValidator(doc *openapi3.T) (*Validator, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{router: router}, nil
+}
+
+// Middleware answers 400 to the requests that do not match the operation
+// they are sent to. Requests to paths or methods missing from the document
+// go through, the router answers them.
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		route, pathParams, err := v.router.FindRoute(request)
+		if err != nil {
+			next.ServeHTTP(writer, request)
+			return
+		}
+
+		if request.ContentLength != 0 && request.Header.Get("Content-Type") == "" {
+			// The handlers read any body as JSON, whatever its announced type.
+			request.Header.Set("Content-Type", "application/json")
+		}
+		err = openapi3filter.ValidateRequest(request.Context(), &openapi3filter.RequestValidationInput{
+			Request:    request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+		})
+		if err != nil {
+			logging.FromContext(request.Context()).Warn("Request not matching the OpenAPI document, error: ", err)
+			problem.Write(writer, http.StatusBadRequest, "invalid_request", "the request does not match the API specification",
+				fieldError(err))
+			return
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// fieldError names the parameter or the body field that failed validation.
+func fieldError(err error) domain.FieldError {
+	var requestErr *openapi3filter.RequestError
+	if !errors.As(err, &requestErr) {
+		return domain.FieldError{Field: "request", Message: err.Error()}
+	}
+
+	field := "body"
+	if requestErr.Parameter != nil {
+		field = requestErr.Parameter.Name
+	}
+	message := requestErr.Reason
+	var schemaErr *openapi3.SchemaError
+	if errors.As(requestErr.Err, &schemaErr) {
+		if pointer := schemaErr.JSONPointer(); requestErr.Parameter == nil && len(pointer) > 0 {
+			field = strings.Join(pointer, ".")
+		}
+		message = schemaErr.Reason
+	} else if requestErr.Err != nil && message == "" {
+		message = requestErr.Err.Error()
+	}
+	return domain.FieldError{Field: field, Message: message}
+}
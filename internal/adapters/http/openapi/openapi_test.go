@@ -0,0 +1,185 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"game-project/internal/adapters/http/problem"
+	"game-project/internal/application/command"
+	"game-project/internal/application/query"
+	"game-project/internal/domain"
+)
+
+func TestLoad(t *testing.T) {
+	if _, err := Load(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/openapi.json", nil)
+	w := httptest.NewRecorder()
+	Handler(w, r)
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("the document should be served as JSON, received status %d and type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !json.Valid(w.Body.Bytes()) {
+		t.Error("the document served is not valid JSON")
+	}
+}
+
+// TestSchemas checks that the schemas of the document list the JSON fields
+// of the types they describe, so that the document follows the types.
+func TestSchemas(t *testing.T) {
+	doc, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := map[string]interface{}{
+		"CreateUser":         command.CreateUser{},
+		"IssueToken":         command.IssueToken{},
+		"UpdateUserState":    command.UpdateUserState{},
+		"UpdateUserFriends":  command.UpdateUserFriends{},
+		"UpdateUserProfile":  command.UpdateUserProfile{},
+		"SendFriendRequest":  command.SendFriendRequest{},
+		"BlockUser":          command.BlockUser{},
+		"SetLogLevel":        command.SetLogLevel{},
+		"User":               query.User{},
+		"Users":              query.Users{},
+		"UserSearch":         query.UserSearch{},
+		"NameChange":         query.NameChange{},
+		"UserProfile":        query.UserProfile{},
+		"UserGameState":      query.UserGameStateQuery{},
+		"GameSession":        query.GameSession{},
+		"GameSessions":       query.GameSessions{},
+		"Friend":             query.Friend{},
+		"UserFriends":        query.UserFriends{},
+		"FriendRank":         query.FriendRank{},
+		"FriendsLeaderboard": query.FriendsLeaderboard{},
+		"FriendsUpdated":     query.FriendsUpdated{},
+		"FriendRequest":      query.FriendRequest{},
+		"FriendRequests":     query.FriendRequests{},
+		"Blocks":             query.Blocks{},
+		"LeaderboardEntry":   query.LeaderboardEntry{},
+		"Leaderboard":        query.Leaderboard{},
+		"UserRank":           query.UserRank{},
+		"Token":              query.Token{},
+		"Health":             query.Health{},
+		"LogLevel":           query.LogLevel{},
+		"Problem":            problem.Details{},
+		"FieldError":         domain.FieldError{},
+	}
+
+	for name, value := range types {
+		schema, ok := doc.Components.Schemas[name]
+		if !ok {
+			t.Errorf("schema %s is missing", name)
+			continue
+		}
+		var properties []string
+		for property := range schema.Value.Properties {
+			properties = append(properties, property)
+		}
+		sort.Strings(properties)
+		fields := jsonFields(reflect.TypeOf(value))
+		sort.Strings(fields)
+		if !reflect.DeepEqual(properties, fields) {
+			t.Errorf("schema %s has the properties %v, but its type has the fields %v", name, properties, fields)
+		}
+	}
+}
+
+// jsonFields lists the names of the fields encoding/json writes for the
+// struct type, with the fields of embedded structs.
+func jsonFields(structType reflect.Type) []string {
+	var fields []string
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.Anonymous {
+			fields = append(fields, jsonFields(field.Type)...)
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+func TestValidator_Middleware(t *testing.T) {
+	doc, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := NewValidator(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received string
+	handler := validator.Middleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		received = string(body)
+		writer.WriteHeader(http.StatusOK)
+	}))
+	userId := "3f6e2ac2-9c1e-4c49-8d76-2d1c6b0f0b1a"
+
+	cases := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedField  string
+	}{
+		{name: "valid body", method: "POST", path: "/user", body: `{"name": "player"}`, expectedStatus: http.StatusOK},
+		{name: "missing required field", method: "POST", path: "/user", body: `{}`, expectedStatus: http.StatusBadRequest, expectedField: "name"},
+		{name: "wrong field type", method: "PUT", path: "/user/" + userId + "/state", body: `{"gamesPlayed": 1, "score": "high"}`,
+			expectedStatus: http.StatusBadRequest, expectedField: "score"},
+		{name: "out of range field", method: "PUT", path: "/user/" + userId + "/state", body: `{"gamesPlayed": 256, "score": 1}`,
+			expectedStatus: http.StatusBadRequest, expectedField: "gamesPlayed"},
+		{name: "invalid path parameter", method: "GET", path: "/user/42", expectedStatus: http.StatusBadRequest, expectedField: "userId"},
+		{name: "query parameter over its maximum", method: "GET", path: "/leaderboard?limit=1000", expectedStatus: http.StatusBadRequest,
+			expectedField: "limit"},
+		{name: "unknown enum value", method: "GET", path: "/leaderboard?period=yearly", expectedStatus: http.StatusBadRequest,
+			expectedField: "period"},
+		{name: "static segment next to a parameter", method: "GET", path: "/user/" + userId + "/friends/leaderboard?period=weekly",
+			expectedStatus: http.StatusOK},
+		{name: "path missing from the document", method: "GET", path: "/unknown", expectedStatus: http.StatusOK},
+	}
+	for _, tc := range cases {
+		received = ""
+		r, _ := http.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		if tc.body != "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != tc.expectedStatus {
+			t.Errorf("%s: wrong status retrieved, should be %d and received %d instead: %s", tc.name, tc.expectedStatus, w.Code, w.Body)
+			continue
+		}
+		if tc.expectedStatus == http.StatusOK && received != tc.body {
+			t.Errorf("%s: the handler should read the body %q, read %q instead", tc.name, tc.body, received)
+		}
+		if tc.expectedField != "" {
+			var details problem.Details
+			json.Unmarshal(w.Body.Bytes(), &details)
+			if details.Code != "invalid_request" || len(details.Fields) != 1 || details.Fields[0].Field != tc.expectedField {
+				t.Errorf("%s: the problem should name the field %s, received %+v instead", tc.name, tc.expectedField, details)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Package problem writes the error responses of the API as RFC 7807 problem
+// details, extended with a stable error code and the offending fields.
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"game-project/internal/domain"
+)
+
+const ContentType = "application/problem+json"
+
+// StatusClientClosedRequest is the non-standard status, borrowed from nginx,
+// recorded for requests whose client went away before the response.
+const StatusClientClosedRequest = 499
+
+// Details is the body of an error response. Code is the machine readable
+// counterpart of Title, clients should branch on it rather than on Detail.
+type Details struct {
+	Type   string              `json:"type"`
+	Title  string              `json:"title"`
+	Status int                 `json:"status"`
+	Detail string              `json:"detail,omitempty"`
+	Code   string              `json:"code"`
+	Fields []domain.FieldError `json:"fields,omitempty"`
+}
+
+// Write answers with a problem of the given status.
+func Write(writer http.ResponseWriter, status int, code string, detail string, fields ...domain.FieldError) {
+	res, _ := json.Marshal(Details{
+		Type:   "about:blank",
+		Title:  title(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+		Fields: fields,
+	})
+
+	writer.Header().Set("Content-Type", ContentType)
+	writer.WriteHeader(status)
+	writer.Write(res)
+}
+
+// WriteError answers with the problem matching err. Domain errors keep their
+// code and message, errors of any other kind are logged and hidden behind a
+// generic 500 so that no internal detail leaks to the client.
+func WriteError(writer http.ResponseWriter, err error) {
+	var domainErr *domain.Error
+	switch {
+	case errors.As(err, &domainErr):
+		Write(writer, Status(err), domainErr.Code, domainErr.Message, domainErr.Fields...)
+	case errors.Is(err, context.DeadlineExceeded):
+		Write(writer, http.StatusGatewayTimeout, "timeout", "the request took too long to complete")
+	case errors.Is(err, context.Canceled):
+		Write(writer, StatusClientClosedRequest, "client_closed_request", "the client closed the request")
+	default:
+		log.Error("Unexpected error: ", err)
+		Write(writer, http.StatusInternalServerError, "internal", "an unexpected error occurred")
+	}
+}
+
+// Invalid answers with a 400 for a malformed parameter, path variable or body.
+func Invalid(writer http.ResponseWriter, field string, message string) {
+	Write(writer, http.StatusBadRequest, "invalid_parameter", fmt.Sprintf("invalid %s", field),
+		domain.FieldError{Field: field, Message: message})
+}
+
+// Status maps an error to its response status.
+func Status(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func title(status int) string {
+	if status == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(status)
+}
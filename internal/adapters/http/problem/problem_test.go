@@ -0,0 +1,109 @@
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"game-project/internal/domain"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Details
+	}{
+		{
+			name: "validation",
+			err:  domain.ValidationError("invalid_name", "the name must not be empty", domain.FieldError{Field: "name", Message: "must not be empty"}),
+			want: Details{Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest, Detail: "the name must not be empty",
+				Code: "invalid_name", Fields: []domain.FieldError{{Field: "name", Message: "must not be empty"}}},
+		},
+		{
+			name: "wrapped not found",
+			err:  fmt.Errorf("loading: %w", domain.NotFoundError("user_not_found", "no user found")),
+			want: Details{Type: "about:blank", Title: "Not Found", Status: http.StatusNotFound, Detail: "no user found", Code: "user_not_found"},
+		},
+		{
+			name: "conflict",
+			err:  domain.ConflictError("name_taken", "the name is already taken"),
+			want: Details{Type: "about:blank", Title: "Conflict", Status: http.StatusConflict, Detail: "the name is already taken", Code: "name_taken"},
+		},
+		{
+			name: "forbidden",
+			err:  domain.ForbiddenError("user_blocked", "blocked"),
+			want: Details{Type: "about:blank", Title: "Forbidden", Status: http.StatusForbidden, Detail: "blocked", Code: "user_blocked"},
+		},
+		{
+			name: "unauthorized",
+			err:  domain.UnauthorizedError("invalid_credentials", "invalid credentials"),
+			want: Details{Type: "about:blank", Title: "Unauthorized", Status: http.StatusUnauthorized, Detail: "invalid credentials", Code: "invalid_credentials"},
+		},
+		{
+			name: "unavailable",
+			err:  fmt.Errorf("loading: %w", domain.UnavailableError("database_unavailable", "the database is unavailable")),
+			want: Details{Type: "about:blank", Title: "Service Unavailable", Status: http.StatusServiceUnavailable,
+				Detail: "the database is unavailable", Code: "database_unavailable"},
+		},
+		{
+			name: "deadline",
+			err:  context.DeadlineExceeded,
+			want: Details{Type: "about:blank", Title: "Gateway Timeout", Status: http.StatusGatewayTimeout,
+				Detail: "the request took too long to complete", Code: "timeout"},
+		},
+		{
+			name: "canceled",
+			err:  context.Canceled,
+			want: Details{Type: "about:blank", Title: "Client Closed Request", Status: StatusClientClosedRequest,
+				Detail: "the client closed the request", Code: "client_closed_request"},
+		},
+		{
+			name: "unexpected error is hidden",
+			err:  errors.New("pq: connection refused on 10.0.0.3"),
+			want: Details{Type: "about:blank", Title: "Internal Server Error", Status: http.StatusInternalServerError,
+				Detail: "an unexpected error occurred", Code: "internal"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			WriteError(w, tt.err)
+
+			if w.Code != tt.want.Status {
+				t.Errorf("status = %d, want %d", w.Code, tt.want.Status)
+			}
+			if got := w.Header().Get("Content-Type"); got != ContentType {
+				t.Errorf("Content-Type = %s, want %s", got, ContentType)
+			}
+			var got Details
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("could not decode body %s: %s", w.Body, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("body = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	w := httptest.NewRecorder()
+	Invalid(w, "userId", "must be a UUID")
+
+	want := Details{Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest, Detail: "invalid userId",
+		Code: "invalid_parameter", Fields: []domain.FieldError{{Field: "userId", Message: "must be a UUID"}}}
+	var got Details
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("could not decode body %s: %s", w.Body, err)
+	}
+	if w.Code != http.StatusBadRequest || !reflect.DeepEqual(got, want) {
+		t.Errorf("Invalid() = %d %+v, want %+v", w.Code, got, want)
+	}
+}
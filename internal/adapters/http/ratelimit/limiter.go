@@ -0,0 +1,150 @@
+// Package ratelimit limits the requests a client may send to a route with a
+// token bucket per route and client. Clients are told when to retry with a
+// 429 answer carrying a Retry-After header.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"game-project/internal/adapters/http/auth"
+	"game-project/internal/adapters/http/problem"
+	"game-project/internal/logging"
+)
+
+// Limit is a token bucket holding Burst tokens, refilled with a token every
+// Interval. Every request takes a token.
+type Limit struct {
+	Interval time.Duration
+	Burst    int
+}
+
+// Take applies the limit to a bucket that is full again at fullAt, the zero
+// time for a bucket never used. It returns when the bucket is full again once
+// a token is taken, or, when it is empty, how long to wait for the next token.
+func (l Limit) Take(fullAt time.Time, now time.Time) (time.Time, time.Duration) {
+	if fullAt.Before(now) {
+		fullAt = now
+	}
+	fullAt = fullAt.Add(l.Interval)
+	if wait := fullAt.Sub(now) - time.Duration(l.Burst)*l.Interval; wait > 0 {
+		return time.Time{}, wait
+	}
+	return fullAt, 0
+}
+
+// Store keeps the buckets. A store backed by a database shares them between
+// every instance of the application.
+type Store interface {
+	// Take removes a token from the bucket of the key. It returns 0 when it
+	// did, or how long to wait for a token when the bucket is empty.
+	Take(ctx context.Context, key string, limit Limit) (time.Duration, error)
+}
+
+// ParseLimits reads routeName:requests/period[:burst] entries, such as
+// updateUserState:60/1m or updateUserState:60/1m:10. The burst defaults to
+// the number of requests allowed per period.
+func ParseLimits(entries []string) (map[string]Limit, error) {
+	limits := make(map[string]Limit, len(entries))
+	for _, entry := range entries {
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return nil, fmt.Errorf("rate limit %q is not routeName:requests/period[:burst]", entry)
+		}
+		rate := strings.SplitN(parts[1], "/", 2)
+		if len(rate) != 2 {
+			return nil, fmt.Errorf("rate limit %q is not routeName:requests/period[:burst]", entry)
+		}
+		requests, err := strconv.Atoi(rate[0])
+		if err != nil || requests <= 0 {
+			return nil, fmt.Errorf("rate limit %q must allow a positive number of requests", entry)
+		}
+		period, err := time.ParseDuration(rate[1])
+		if err != nil || period <= 0 {
+			return nil, fmt.Errorf("rate limit %q must have a positive period", entry)
+		}
+		burst := requests
+		if len(parts) == 3 {
+			burst, err = strconv.Atoi(parts[2])
+			if err != nil || burst <= 0 {
+				return nil, fmt.Errorf("rate limit %q must have a positive burst", entry)
+			}
+		}
+		if _, ok := limits[parts[0]]; ok {
+			return nil, fmt.Errorf("route %s is limited twice", parts[0])
+		}
+		limits[parts[0]] = Limit{Interval: period / time.Duration(requests), Burst: burst}
+	}
+	return limits, nil
+}
+
+type Limiter struct {
+	store  Store
+	limits map[string]Limit
+}
+
+// NewLimiter limits the routes, by name, to the given limits. Other routes
+// are not limited.
+func NewLimiter(store Store, limits map[string]Limit) *Limiter {
+	return &Limiter{store: store, limits: limits}
+}
+
+// Middleware takes a token from the bucket of the client for the route of
+// the request, and answers 429 when there is none left. Requests go through
+// when the store fails, the limiter must not take the application down with
+// it. It must be used as a mux middleware, after the authentication ones.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		route := mux.CurrentRoute(request)
+		if route == nil {
+			next.ServeHTTP(writer, request)
+			return
+		}
+		limit, ok := l.limits[route.GetName()]
+		if !ok {
+			next.ServeHTTP(writer, request)
+			return
+		}
+
+		client := clientKey(request)
+		wait, err := l.store.Take(request.Context(), route.GetName()+"\n"+client, limit)
+		if err != nil {
+			logging.FromContext(request.Context()).Error("Could not apply the rate limit, letting the request through, error: ", err)
+			next.ServeHTTP(writer, request)
+			return
+		}
+		if wait > 0 {
+			logging.FromContext(request.Context()).Warnf("Rate limit of %s exceeded by %s", route.GetName(), client)
+			writer.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			problem.Write(writer, http.StatusTooManyRequests, "rate_limited", "too many requests, retry later")
+			return
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// clientKey identifies who sent the request: the game server that signed
+// it, the authenticated user, or else the remote address. A game server acts
+// on behalf of many players, it gets a bucket per {userId} it acts on.
+func clientKey(request *http.Request) string {
+	if serverId, ok := auth.ServerFromContext(request.Context()); ok {
+		return "server:" + serverId + "\n" + mux.Vars(request)["userId"]
+	}
+	if claims, ok := auth.ClaimsFromContext(request.Context()); ok {
+		return "user:" + claims.Subject
+	}
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		host = request.RemoteAddr
+	}
+	return "ip:" + host
+}
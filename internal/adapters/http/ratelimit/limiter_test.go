@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+
+	"game-project/internal/adapters/http/auth"
+)
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits([]string{"updateUserState:60/1m", "updateUserFriends:10/1m:2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limits["updateUserState"] != (Limit{Interval: time.Second, Burst: 60}) {
+		t.Errorf("wrong limit parsed for updateUserState: %+v", limits["updateUserState"])
+	}
+	if limits["updateUserFriends"] != (Limit{Interval: 6 * time.Second, Burst: 2}) {
+		t.Errorf("wrong limit parsed for updateUserFriends: %+v", limits["updateUserFriends"])
+	}
+
+	invalid := []string{"updateUserState", ":60/1m", "updateUserState:60", "updateUserState:0/1m",
+		"updateUserState:60/-1m", "updateUserState:60/minute", "updateUserState:60/1m:0", "updateUserState:60/1m:2:3"}
+	for _, entry := range invalid {
+		if _, err := ParseLimits([]string{entry}); err == nil {
+			t.Errorf("%q should be rejected", entry)
+		}
+	}
+	if _, err := ParseLimits([]string{"updateUserState:60/1m", "updateUserState:1/1s"}); err == nil {
+		t.Error("a route limited twice should be rejected")
+	}
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Interval: time.Second, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		if wait, _ := store.Take(context.Background(), "k", limit); wait != 0 {
+			t.Fatalf("request %d should fit in the burst, waiting %s instead", i+1, wait)
+		}
+	}
+	if wait, _ := store.Take(context.Background(), "k", limit); wait != time.Second {
+		t.Fatalf("an empty bucket should wait for the next token, waiting %s instead", wait)
+	}
+	if wait, _ := store.Take(context.Background(), "other", limit); wait != 0 {
+		t.Fatalf("buckets should not be shared between keys, waiting %s instead", wait)
+	}
+
+	now = now.Add(1500 * time.Millisecond)
+	if wait, _ := store.Take(context.Background(), "k", limit); wait != 0 {
+		t.Fatalf("a refilled token should be taken, waiting %s instead", wait)
+	}
+	if wait, _ := store.Take(context.Background(), "k", limit); wait != 500*time.Millisecond {
+		t.Fatalf("the wait should count from the last refill, waiting %s instead", wait)
+	}
+
+	now = now.Add(time.Hour)
+	store.Take(context.Background(), "k", limit)
+	if len(store.buckets) != 1 {
+		t.Errorf("full buckets should be pruned, %d buckets kept", len(store.buckets))
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit Limit) (time.Duration, error) {
+	return 0, errors.New("store unavailable")
+}
+
+func TestLimiter_Middleware(t *testing.T) {
+	keys, _ := auth.ParseKeySet("k1:secret", "")
+	authenticator := auth.NewAuthenticator(keys, time.Hour, nil)
+	userId, _ := uuid.NewV4()
+	token, _, _ := authenticator.Issue(userId)
+
+	newRouter := func(store Store) *mux.Router {
+		limiter := NewLimiter(store, map[string]Limit{"updateUserState": {Interval: time.Minute, Burst: 1}})
+		r := mux.NewRouter()
+		r.Use(authenticator.Middleware)
+		r.Use(limiter.Middleware)
+		ok := func(writer http.ResponseWriter, request *http.Request) { writer.WriteHeader(http.StatusOK) }
+		r.HandleFunc("/user/{userId}/state", ok).Methods("PUT").Name("updateUserState")
+		r.HandleFunc("/user/{userId}/state", ok).Methods("GET").Name("loadUserState")
+		return r
+	}
+	send := func(r *mux.Router, method string, remoteAddr string, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/user/"+userId.String()+"/state", nil)
+		req.RemoteAddr = remoteAddr
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	r := newRouter(NewMemoryStore())
+	cases := []struct {
+		name           string
+		method         string
+		remoteAddr     string
+		token          string
+		expectedStatus int
+	}{
+		{name: "first anonymous request", method: "PUT", remoteAddr: "10.0.0.1:1234", expectedStatus: http.StatusOK},
+		{name: "same address, other port", method: "PUT", remoteAddr: "10.0.0.1:5678", expectedStatus: http.StatusTooManyRequests},
+		{name: "other address", method: "PUT", remoteAddr: "10.0.0.2:1234", expectedStatus: http.StatusOK},
+		{name: "authenticated from a limited address", method: "PUT", remoteAddr: "10.0.0.1:1234", token: token, expectedStatus: http.StatusOK},
+		{name: "authenticated again", method: "PUT", remoteAddr: "10.0.0.3:1234", token: token, expectedStatus: http.StatusTooManyRequests},
+		{name: "unlimited route", method: "GET", remoteAddr: "10.0.0.1:1234", expectedStatus: http.StatusOK},
+	}
+	for _, tc := range cases {
+		w := send(r, tc.method, tc.remoteAddr, tc.token)
+		if w.Code != tc.expectedStatus {
+			t.Errorf("%s: wrong status retrieved, should be %d and received %d instead", tc.name, tc.expectedStatus, w.Code)
+		}
+		if tc.expectedStatus == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "60" {
+			t.Errorf("%s: wrong Retry-After, should be 60 and received %q instead", tc.name, w.Header().Get("Retry-After"))
+		}
+	}
+
+	if w := send(newRouter(failingStore{}), "PUT", "10.0.0.1:1234", ""); w.Code != http.StatusOK {
+		t.Errorf("requests should go through when the store fails, received %d instead", w.Code)
+	}
+}
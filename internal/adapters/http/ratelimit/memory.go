@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps the buckets of a single instance. Each bucket is stored
+// as the time it is full again, full buckets are forgotten.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]time.Time
+	nextPrune time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]time.Time{}, now: time.Now}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	fullAt, wait := limit.Take(s.buckets[key], now)
+	if wait == 0 {
+		s.buckets[key] = fullAt
+	}
+
+	if now.After(s.nextPrune) {
+		for k, fullAt := range s.buckets {
+			if !fullAt.After(now) {
+				delete(s.buckets, k)
+			}
+		}
+		s.nextPrune = now.Add(time.Minute)
+	}
+
+	return wait, nil
+}
@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"strings"
+	"unicode"
+)
+
+// similarityThreshold is the default pg_trgm.similarity_threshold the
+// postgresql adapter matches names with.
+const similarityThreshold = 0.3
+
+// trigrams splits the text like pg_trgm does: every word of letters and
+// digits is padded with two spaces in front and one behind, and cut into
+// the set of its three-rune sequences.
+func trigrams(text string) map[string]bool {
+	set := make(map[string]bool)
+	words := strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// similarity is the pg_trgm similarity of both texts, the share of their
+// trigrams they have in common.
+func similarity(a string, b string) float64 {
+	aSet, bSet := trigrams(a), trigrams(b)
+	common := 0
+	for trigram := range aSet {
+		if bSet[trigram] {
+			common++
+		}
+	}
+	total := len(aSet) + len(bSet) - common
+	if total == 0 {
+		return 0
+	}
+	return float64(common) / float64(total)
+}
@@ -0,0 +1,861 @@
+// Package memory keeps the users in memory, for local development and tests
+// that should not need a database. It follows the semantics of the
+// postgresql adapter, constraints included, but loses everything on restart.
+package memory
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/domain"
+)
+
+// The errors the postgresql adapter translates its constraint violations to.
+var (
+	errNameTaken           = domain.ConflictError("name_taken", "the name is already taken")
+	errFriendRequestExists = domain.ConflictError("friend_request_exists", "a pending friend request already exists")
+	errUserNotFound        = domain.NotFoundError("user_not_found", "a referenced user does not exist")
+	errNotFound            = domain.NotFoundError("not_found", "no matching record found")
+	errInvalidValue        = domain.ValidationError("invalid_value", "a value is not valid")
+)
+
+type UserRepositoryImpl struct {
+	mu          sync.RWMutex
+	users       map[uuid.UUID]*domain.User
+	nameHistory map[uuid.UUID][]*domain.NameChange
+	// friends holds a set per user, like the rows of user_friends a
+	// friendship is stored once per direction.
+	friends map[uuid.UUID]map[uuid.UUID]bool
+	// blocks holds when each user blocked each of the users they blocked.
+	blocks         map[uuid.UUID]map[uuid.UUID]time.Time
+	friendRequests map[uuid.UUID]*domain.FriendRequest
+	sessions       []*domain.GameSession
+	lastSessionId  int64
+}
+
+func NewUserRepository() *UserRepositoryImpl {
+	return &UserRepositoryImpl{
+		users:          make(map[uuid.UUID]*domain.User),
+		nameHistory:    make(map[uuid.UUID][]*domain.NameChange),
+		friends:        make(map[uuid.UUID]map[uuid.UUID]bool),
+		blocks:         make(map[uuid.UUID]map[uuid.UUID]time.Time),
+		friendRequests: make(map[uuid.UUID]*domain.FriendRequest),
+	}
+}
+
+func (r *UserRepositoryImpl) List(ctx context.Context, listing domain.UserListing) ([]*domain.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var after *domain.User
+	if listing.After != nil {
+		after = &domain.User{
+			Id:        listing.After.Id,
+			Name:      listing.After.Name,
+			Score:     sql.NullInt64{Int64: listing.After.Score, Valid: true},
+			CreatedAt: listing.After.CreatedAt,
+		}
+	}
+	var usrLst []*domain.User
+	for _, usr := range r.users {
+		if usr.Score.Int64 < listing.Filter.MinScore || int64(usr.GamesPlayed.Int32) < listing.Filter.MinGamesPlayed {
+			continue
+		}
+		if after != nil && !listedBefore(listing.Sort, after, usr) {
+			continue
+		}
+		usrLst = append(usrLst, usr)
+	}
+	sort.Slice(usrLst, func(i, j int) bool { return listedBefore(listing.Sort, usrLst[i], usrLst[j]) })
+
+	return copyUsers(pageUsers(usrLst, 0, listing.Limit)), nil
+}
+
+func (r *UserRepositoryImpl) Create(ctx context.Context, uName string, secretHash []byte) (*domain.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.nameTaken(uName, uuid.Nil) {
+		return nil, errNameTaken
+	}
+	id, _ := uuid.NewV4()
+	user := &domain.User{
+		Id:         id,
+		Name:       uName,
+		SecretHash: append([]byte(nil), secretHash...),
+		CreatedAt:  now(),
+	}
+	r.users[id] = user
+
+	return copyUser(user), nil
+}
+
+func (r *UserRepositoryImpl) UpdateUserState(ctx context.Context, userId uuid.UUID, gamesPlayed uint8, session *domain.GameSession) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usr, ok := r.users[userId]
+	if !ok {
+		return errUserNotFound
+	}
+	usr.GamesPlayed = sql.NullInt32{Int32: int32(gamesPlayed), Valid: true}
+	// Like GREATEST, a missing score is replaced by the new one.
+	if !usr.Score.Valid || int64(session.Score) > usr.Score.Int64 {
+		usr.Score = sql.NullInt64{Int64: int64(session.Score), Valid: true}
+	}
+
+	r.lastSessionId++
+	session.Id = r.lastSessionId
+	session.UserId = userId
+	session.SubmittedAt = now()
+	stored := *session
+	stored.Metadata = append([]byte(nil), session.Metadata...)
+	r.sessions = append(r.sessions, &stored)
+
+	return nil
+}
+
+func (r *UserRepositoryImpl) ListSessions(ctx context.Context, userId uuid.UUID, cursor *domain.SessionCursor, limit uint) ([]*domain.GameSession, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var sessionLst []*domain.GameSession
+	for _, session := range r.sessions {
+		if session.UserId != userId {
+			continue
+		}
+		if cursor != nil && !sessionBefore(cursor.SubmittedAt, cursor.Id, session) {
+			continue
+		}
+		copied := *session
+		copied.Metadata = append([]byte(nil), session.Metadata...)
+		sessionLst = append(sessionLst, &copied)
+	}
+	sort.Slice(sessionLst, func(i, j int) bool {
+		return sessionBefore(sessionLst[i].SubmittedAt, sessionLst[i].Id, sessionLst[j])
+	})
+	if uint(len(sessionLst)) > limit {
+		sessionLst = sessionLst[:limit]
+	}
+
+	return sessionLst, nil
+}
+
+func (r *UserRepositoryImpl) FindUser(ctx context.Context, userId uuid.UUID) (*domain.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	usr, ok := r.users[userId]
+	if !ok {
+		return nil, nil
+	}
+
+	return copyUser(usr), nil
+}
+
+func (r *UserRepositoryImpl) ExistingUsers(ctx context.Context, userIds []uuid.UUID) ([]uuid.UUID, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var existingLst []uuid.UUID
+	seen := make(map[uuid.UUID]bool, len(userIds))
+	for _, id := range userIds {
+		if _, ok := r.users[id]; ok && !seen[id] {
+			existingLst = append(existingLst, id)
+			seen[id] = true
+		}
+	}
+
+	return existingLst, nil
+}
+
+func (r *UserRepositoryImpl) Search(ctx context.Context, term string, viewerId uuid.UUID, limit uint, offset uint) ([]*domain.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type match struct {
+		usr        *domain.User
+		prefix     bool
+		similarity float64
+	}
+	term = strings.ToLower(term)
+	var matches []match
+	for _, usr := range r.users {
+		if r.blocked(viewerId, usr.Id) {
+			continue
+		}
+		name := strings.ToLower(usr.Name)
+		m := match{usr: usr, prefix: strings.HasPrefix(name, term), similarity: similarity(name, term)}
+		if m.prefix || m.similarity >= similarityThreshold {
+			matches = append(matches, m)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.prefix != b.prefix {
+			return a.prefix
+		}
+		if a.similarity != b.similarity {
+			return a.similarity > b.similarity
+		}
+		return a.usr.Name < b.usr.Name
+	})
+
+	var usrLst []*domain.User
+	for _, m := range matches {
+		usrLst = append(usrLst, m.usr)
+	}
+
+	return copyUsers(pageUsers(usrLst, offset, limit)), nil
+}
+
+func (r *UserRepositoryImpl) UpdateProfile(ctx context.Context, user *domain.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[user.Id]
+	if !ok {
+		return errNotFound
+	}
+	if user.Name != stored.Name {
+		if r.nameTaken(user.Name, user.Id) {
+			return errNameTaken
+		}
+		changedAt := now()
+		r.nameHistory[user.Id] = append(r.nameHistory[user.Id], &domain.NameChange{Name: stored.Name, ChangedAt: changedAt})
+		stored.Name = user.Name
+		stored.NameChangedAt = sql.NullTime{Time: changedAt, Valid: true}
+	}
+	stored.Country = user.Country
+	stored.AvatarUrl = user.AvatarUrl
+	stored.Bio = user.Bio
+	user.NameChangedAt = stored.NameChangedAt
+
+	return nil
+}
+
+func (r *UserRepositoryImpl) ListNameHistory(ctx context.Context, userId uuid.UUID) ([]*domain.NameChange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	history := r.nameHistory[userId]
+	historyLst := make([]*domain.NameChange, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		change := *history[i]
+		historyLst = append(historyLst, &change)
+	}
+
+	return historyLst, nil
+}
+
+func (r *UserRepositoryImpl) UpdateSecret(ctx context.Context, userId uuid.UUID, secretHash []byte) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usr, ok := r.users[userId]
+	if !ok {
+		return 0, nil
+	}
+	usr.SecretHash = append([]byte(nil), secretHash...)
+
+	return 1, nil
+}
+
+// Delete cascades to everything referencing the user, like the foreign keys
+// of the postgresql schema.
+func (r *UserRepositoryImpl) Delete(ctx context.Context, userId uuid.UUID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[userId]; !ok {
+		return 0, nil
+	}
+	delete(r.users, userId)
+	delete(r.nameHistory, userId)
+	delete(r.friends, userId)
+	for _, friends := range r.friends {
+		delete(friends, userId)
+	}
+	delete(r.blocks, userId)
+	for _, blocked := range r.blocks {
+		delete(blocked, userId)
+	}
+	for id, request := range r.friendRequests {
+		if request.FromUserId == userId || request.ToUserId == userId {
+			delete(r.friendRequests, id)
+		}
+	}
+	sessions := r.sessions[:0]
+	for _, session := range r.sessions {
+		if session.UserId != userId {
+			sessions = append(sessions, session)
+		}
+	}
+	r.sessions = sessions
+
+	return 1, nil
+}
+
+func (r *UserRepositoryImpl) UpdateFriends(ctx context.Context, userId uuid.UUID, update domain.FriendsUpdate) (int64, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Check the references first, a failing insert rolls the whole update back.
+	if len(update.Add) > 0 {
+		if _, ok := r.users[userId]; !ok {
+			return 0, 0, errUserNotFound
+		}
+		for _, friendId := range update.Add {
+			if _, ok := r.users[friendId]; !ok {
+				return 0, 0, errUserNotFound
+			}
+		}
+	}
+
+	var removed int64
+	if update.Replace {
+		keep := make(map[uuid.UUID]bool, len(update.Add))
+		for _, friendId := range update.Add {
+			keep[friendId] = true
+		}
+		for friendId := range r.friends[userId] {
+			if !keep[friendId] {
+				r.unfriend(userId, friendId)
+				removed++
+			}
+		}
+	} else {
+		for _, friendId := range update.Remove {
+			if r.friends[userId][friendId] {
+				removed++
+			}
+			r.unfriend(userId, friendId)
+		}
+	}
+
+	var added int64
+	for _, friendId := range update.Add {
+		if r.blocked(userId, friendId) || r.blocked(friendId, userId) || r.friends[userId][friendId] {
+			continue
+		}
+		// Like removing, adding a friend makes the friendship mutual.
+		r.befriend(userId, friendId)
+		r.befriend(friendId, userId)
+		added++
+	}
+
+	return added, removed, nil
+}
+
+func (r *UserRepositoryImpl) ListFriends(ctx context.Context, userId uuid.UUID) ([]*domain.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var friendLst []*domain.User
+	for friendId := range r.friends[userId] {
+		usr, ok := r.users[friendId]
+		if !ok || r.blocked(userId, friendId) {
+			continue
+		}
+		friendLst = append(friendLst, &domain.User{Id: usr.Id, Name: usr.Name, Score: usr.Score})
+	}
+	sort.Slice(friendLst, func(i, j int) bool { return friendLst[i].Name < friendLst[j].Name })
+
+	return friendLst, nil
+}
+
+func (r *UserRepositoryImpl) BlockUser(ctx context.Context, userId uuid.UUID, blockedId uuid.UUID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if userId == blockedId {
+		return 0, errInvalidValue
+	}
+	if !r.exist(userId, blockedId) {
+		return 0, errUserNotFound
+	}
+	var touchedRows int64
+	if !r.blocked(userId, blockedId) {
+		if r.blocks[userId] == nil {
+			r.blocks[userId] = make(map[uuid.UUID]time.Time)
+		}
+		r.blocks[userId][blockedId] = now()
+		touchedRows = 1
+	}
+	r.unfriend(userId, blockedId)
+	for _, request := range r.friendRequests {
+		if request.Status == domain.FriendRequestPending && betweenUsers(request, userId, blockedId) {
+			request.Status = domain.FriendRequestCancelled
+			request.UpdatedAt = now()
+		}
+	}
+
+	return touchedRows, nil
+}
+
+func (r *UserRepositoryImpl) UnblockUser(ctx context.Context, userId uuid.UUID, blockedId uuid.UUID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.blocked(userId, blockedId) {
+		return 0, nil
+	}
+	delete(r.blocks[userId], blockedId)
+
+	return 1, nil
+}
+
+func (r *UserRepositoryImpl) ListBlocks(ctx context.Context, userId uuid.UUID) ([]*domain.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var blockedLst []*domain.User
+	for blockedId := range r.blocks[userId] {
+		if usr, ok := r.users[blockedId]; ok {
+			blockedLst = append(blockedLst, &domain.User{Id: usr.Id, Name: usr.Name})
+		}
+	}
+	blockedAt := r.blocks[userId]
+	sort.Slice(blockedLst, func(i, j int) bool {
+		return blockedAt[blockedLst[i].Id].After(blockedAt[blockedLst[j].Id])
+	})
+
+	return blockedLst, nil
+}
+
+func (r *UserRepositoryImpl) BlockedAmong(ctx context.Context, userId uuid.UUID, otherIds []uuid.UUID) ([]uuid.UUID, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	others := make(map[uuid.UUID]bool, len(otherIds))
+	for _, id := range otherIds {
+		others[id] = true
+	}
+	var blockedLst []uuid.UUID
+	for otherId := range others {
+		if r.blocked(userId, otherId) {
+			blockedLst = append(blockedLst, otherId)
+		}
+		if r.blocked(otherId, userId) {
+			blockedLst = append(blockedLst, otherId)
+		}
+	}
+
+	return blockedLst, nil
+}
+
+func (r *UserRepositoryImpl) CreateFriendRequest(ctx context.Context, fromUserId uuid.UUID, toUserId uuid.UUID) (*domain.FriendRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if fromUserId == toUserId {
+		return nil, errInvalidValue
+	}
+	if !r.exist(fromUserId, toUserId) {
+		return nil, errUserNotFound
+	}
+	for _, request := range r.friendRequests {
+		if request.Status == domain.FriendRequestPending && betweenUsers(request, fromUserId, toUserId) {
+			return nil, errFriendRequestExists
+		}
+	}
+	id, _ := uuid.NewV4()
+	createdAt := now()
+	request := &domain.FriendRequest{
+		Id:         id,
+		FromUserId: fromUserId,
+		ToUserId:   toUserId,
+		Status:     domain.FriendRequestPending,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}
+	r.friendRequests[id] = request
+
+	copied := *request
+	return &copied, nil
+}
+
+func (r *UserRepositoryImpl) FindFriendRequest(ctx context.Context, requestId uuid.UUID) (*domain.FriendRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	request, ok := r.friendRequests[requestId]
+	if !ok {
+		return nil, nil
+	}
+
+	copied := *request
+	return &copied, nil
+}
+
+func (r *UserRepositoryImpl) ListFriendRequests(ctx context.Context, userId uuid.UUID, direction domain.FriendRequestDirection) ([]*domain.FriendRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var requestLst []*domain.FriendRequest
+	for _, request := range r.friendRequests {
+		if request.Status != domain.FriendRequestPending {
+			continue
+		}
+		if (direction == domain.FriendRequestOutgoing && request.FromUserId == userId) ||
+			(direction != domain.FriendRequestOutgoing && request.ToUserId == userId) {
+			copied := *request
+			requestLst = append(requestLst, &copied)
+		}
+	}
+	sort.Slice(requestLst, func(i, j int) bool { return requestLst[i].CreatedAt.After(requestLst[j].CreatedAt) })
+
+	return requestLst, nil
+}
+
+func (r *UserRepositoryImpl) ResolveFriendRequest(ctx context.Context, requestId uuid.UUID, status domain.FriendRequestStatus) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	request, ok := r.friendRequests[requestId]
+	if !ok || request.Status != domain.FriendRequestPending {
+		return 0, nil
+	}
+	switch status {
+	case domain.FriendRequestPending, domain.FriendRequestAccepted, domain.FriendRequestDeclined, domain.FriendRequestCancelled:
+	default:
+		return 0, errInvalidValue
+	}
+	request.Status = status
+	request.UpdatedAt = now()
+	if status == domain.FriendRequestAccepted {
+		r.befriend(request.FromUserId, request.ToUserId)
+		r.befriend(request.ToUserId, request.FromUserId)
+	}
+
+	return 1, nil
+}
+
+func (r *UserRepositoryImpl) Leaderboard(ctx context.Context, since *time.Time, viewerId uuid.UUID, limit uint, offset uint) ([]*domain.RankedUser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var rankedLst []*domain.RankedUser
+	for _, usr := range r.ranked(since) {
+		if !r.blocked(viewerId, usr.Id) {
+			rankedLst = append(rankedLst, usr)
+		}
+	}
+
+	return pageRanked(rankedLst, offset, limit), nil
+}
+
+func (r *UserRepositoryImpl) LeaderboardAround(ctx context.Context, since *time.Time, viewerId uuid.UUID, userId uuid.UUID, radius uint) ([]*domain.RankedUser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ranked := r.ranked(since)
+	position := -1
+	for i, usr := range ranked {
+		if usr.Id == userId {
+			position = i
+		}
+	}
+	if position < 0 {
+		return nil, nil
+	}
+
+	var rankedLst []*domain.RankedUser
+	for i, usr := range ranked {
+		distance := i - position
+		if distance < 0 {
+			distance = -distance
+		}
+		if uint(distance) <= radius && (usr.Id == userId || !r.blocked(viewerId, usr.Id)) {
+			rankedLst = append(rankedLst, usr)
+		}
+	}
+
+	return rankedLst, nil
+}
+
+func (r *UserRepositoryImpl) FriendsLeaderboard(ctx context.Context, since *time.Time, userId uuid.UUID) ([]*domain.RankedUser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var members []*domain.User
+	if usr, ok := r.users[userId]; ok {
+		members = append(members, usr)
+	}
+	for friendId := range r.friends[userId] {
+		if usr, ok := r.users[friendId]; ok && friendId != userId && !r.blocked(userId, friendId) {
+			members = append(members, usr)
+		}
+	}
+
+	rankedLst := r.rank(members, since)
+	var own int64
+	for _, usr := range rankedLst {
+		if usr.Id == userId {
+			own = usr.Score.Int64
+		}
+	}
+	for _, usr := range rankedLst {
+		usr.Delta = usr.Score.Int64 - own
+	}
+
+	return rankedLst, nil
+}
+
+// ranked ranks the users by their score over the period. Users without a
+// session in the period are left out, the all-time ranking holds everyone.
+func (r *UserRepositoryImpl) ranked(since *time.Time) []*domain.RankedUser {
+	usrLst := make([]*domain.User, 0, len(r.users))
+	for _, usr := range r.users {
+		if since == nil || r.periodScore(usr, since).Valid {
+			usrLst = append(usrLst, usr)
+		}
+	}
+
+	return r.rank(usrLst, since)
+}
+
+// rank orders the users by score, highest first, then by name. Users with
+// the same score share the rank of the first of them, like RANK() does.
+func (r *UserRepositoryImpl) rank(usrLst []*domain.User, since *time.Time) []*domain.RankedUser {
+	rankedLst := make([]*domain.RankedUser, 0, len(usrLst))
+	for _, usr := range usrLst {
+		rankedLst = append(rankedLst, &domain.RankedUser{
+			User: domain.User{Id: usr.Id, Name: usr.Name, Score: r.periodScore(usr, since)},
+		})
+	}
+	sort.Slice(rankedLst, func(i, j int) bool {
+		a, b := rankedLst[i], rankedLst[j]
+		if a.Score.Int64 != b.Score.Int64 {
+			return a.Score.Int64 > b.Score.Int64
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return uuidLess(a.Id, b.Id)
+	})
+	for i, usr := range rankedLst {
+		usr.Rank = int64(i + 1)
+		if i > 0 && usr.Score.Int64 == rankedLst[i-1].Score.Int64 {
+			usr.Rank = rankedLst[i-1].Rank
+		}
+	}
+
+	return rankedLst
+}
+
+// periodScore is the best score of the user since the given time, or their
+// all-time best score when since is nil.
+func (r *UserRepositoryImpl) periodScore(usr *domain.User, since *time.Time) sql.NullInt64 {
+	if since == nil {
+		return usr.Score
+	}
+	var best sql.NullInt64
+	for _, session := range r.sessions {
+		if session.UserId != usr.Id || session.SubmittedAt.Before(*since) {
+			continue
+		}
+		if !best.Valid || int64(session.Score) > best.Int64 {
+			best = sql.NullInt64{Int64: int64(session.Score), Valid: true}
+		}
+	}
+
+	return best
+}
+
+func (r *UserRepositoryImpl) nameTaken(name string, exceptId uuid.UUID) bool {
+	for id, usr := range r.users {
+		if usr.Name == name && id != exceptId {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *UserRepositoryImpl) exist(userIds ...uuid.UUID) bool {
+	for _, id := range userIds {
+		if _, ok := r.users[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *UserRepositoryImpl) blocked(userId uuid.UUID, blockedId uuid.UUID) bool {
+	_, ok := r.blocks[userId][blockedId]
+	return ok
+}
+
+func (r *UserRepositoryImpl) befriend(userId uuid.UUID, friendId uuid.UUID) {
+	if r.friends[userId] == nil {
+		r.friends[userId] = make(map[uuid.UUID]bool)
+	}
+	r.friends[userId][friendId] = true
+}
+
+// unfriend ends the friendship in both directions.
+func (r *UserRepositoryImpl) unfriend(userId uuid.UUID, friendId uuid.UUID) {
+	delete(r.friends[userId], friendId)
+	delete(r.friends[friendId], userId)
+}
+
+func betweenUsers(request *domain.FriendRequest, userId uuid.UUID, otherId uuid.UUID) bool {
+	return (request.FromUserId == userId && request.ToUserId == otherId) ||
+		(request.FromUserId == otherId && request.ToUserId == userId)
+}
+
+// listedBefore tells whether a comes before b in the listing order, the id
+// breaking ties in the same direction as the sorted value.
+func listedBefore(sort domain.UserSort, a *domain.User, b *domain.User) bool {
+	switch sort {
+	case domain.UserSortScore:
+		if a.Score.Int64 != b.Score.Int64 {
+			return a.Score.Int64 > b.Score.Int64
+		}
+		return uuidLess(b.Id, a.Id)
+	case domain.UserSortCreated:
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return uuidLess(b.Id, a.Id)
+	default:
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return uuidLess(a.Id, b.Id)
+	}
+}
+
+// sessionBefore tells whether the session comes after the given position in
+// the most recent first order of the sessions.
+func sessionBefore(submittedAt time.Time, id int64, session *domain.GameSession) bool {
+	if !session.SubmittedAt.Equal(submittedAt) {
+		return session.SubmittedAt.Before(submittedAt)
+	}
+	return session.Id < id
+}
+
+// uuidLess compares the ids byte by byte, the way Postgres orders uuids.
+func uuidLess(a uuid.UUID, b uuid.UUID) bool {
+	return bytes.Compare(a.Bytes(), b.Bytes()) < 0
+}
+
+// pageOffset returns the bounds of the page of the given length.
+func pageOffset(length int, offset uint, limit uint) (int, int) {
+	if offset >= uint(length) {
+		return length, length
+	}
+	end := uint(length)
+	if end-offset > limit {
+		end = offset + limit
+	}
+	return int(offset), int(end)
+}
+
+func pageUsers(usrLst []*domain.User, offset uint, limit uint) []*domain.User {
+	start, end := pageOffset(len(usrLst), offset, limit)
+	return usrLst[start:end]
+}
+
+func pageRanked(rankedLst []*domain.RankedUser, offset uint, limit uint) []*domain.RankedUser {
+	start, end := pageOffset(len(rankedLst), offset, limit)
+	return rankedLst[start:end]
+}
+
+func copyUser(usr *domain.User) *domain.User {
+	copied := *usr
+	copied.SecretHash = append([]byte(nil), usr.SecretHash...)
+	return &copied
+}
+
+func copyUsers(usrLst []*domain.User) []*domain.User {
+	copied := make([]*domain.User, 0, len(usrLst))
+	for _, usr := range usrLst {
+		copied = append(copied, copyUser(usr))
+	}
+	return copied
+}
+
+// now mirrors the microsecond precision of Postgres timestamps.
+func now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/domain"
+	"game-project/internal/domain/domaintest"
+)
+
+func TestUserRepositoryImpl_Contract(t *testing.T) {
+	domaintest.UserRepositoryContract(t, func(t *testing.T) domain.UserRepository {
+		return NewUserRepository()
+	})
+}
+
+func createUsers(t *testing.T, repository *UserRepositoryImpl, names ...string) []uuid.UUID {
+	t.Helper()
+	var ids []uuid.UUID
+	for _, name := range names {
+		user, err := repository.Create(context.Background(), name, nil)
+		if err != nil {
+			t.Fatalf("creating %s: %v", name, err)
+		}
+		ids = append(ids, user.Id)
+	}
+	return ids
+}
+
+func TestUserRepositoryImpl_Constraints(t *testing.T) {
+	ctx := context.Background()
+	repository := NewUserRepository()
+	ids := createUsers(t, repository, "jake", "finn")
+	missingId, _ := uuid.NewV4()
+
+	cases := []struct {
+		name     string
+		call     func() error
+		wantKind error
+		wantCode string
+	}{
+		{
+			name:     "taken name",
+			call:     func() error { _, err := repository.Create(ctx, "jake", nil); return err },
+			wantKind: domain.ErrConflict,
+			wantCode: "name_taken",
+		},
+		{
+			name:     "rename to a taken name",
+			call:     func() error { return repository.UpdateProfile(ctx, &domain.User{Id: ids[1], Name: "jake"}) },
+			wantKind: domain.ErrConflict,
+			wantCode: "name_taken",
+		},
+		{
+			name: "befriend a missing user",
+			call: func() error {
+				_, _, err := repository.UpdateFriends(ctx, ids[0], domain.FriendsUpdate{Add: []uuid.UUID{missingId}})
+				return err
+			},
+			wantKind: domain.ErrNotFound,
+			wantCode: "user_not_found",
+		},
+		{
+			name:     "block oneself",
+			call:     func() error { _, err := repository.BlockUser(ctx, ids[0], ids[0]); return err },
+			wantKind: domain.ErrValidation,
+			wantCode: "invalid_value",
+		},
+		{
+			name: "second pending request",
+			call: func() error {
+				if _, err := repository.CreateFriendRequest(ctx, ids[0], ids[1]); err != nil {
+					return err
+				}
+				_, err := repository.CreateFriendRequest(ctx, ids[1], ids[0])
+				return err
+			},
+			wantKind: domain.ErrConflict,
+			wantCode: "friend_request_exists",
+		},
+	}
+
+	for _, tc := range cases {
+		err := tc.call()
+		var domainErr *domain.Error
+		if !errors.Is(err, tc.wantKind) || !errors.As(err, &domainErr) || domainErr.Code != tc.wantCode {
+			t.Errorf("%s: expected a %v error with code %s, actual: %v", tc.name, tc.wantKind, tc.wantCode, err)
+		}
+	}
+}
+
+func TestUserRepositoryImpl_DeleteCascades(t *testing.T) {
+	ctx := context.Background()
+	repository := NewUserRepository()
+	ids := createUsers(t, repository, "jake", "finn", "bubblegum")
+
+	repository.UpdateUserState(ctx, ids[0], 1, &domain.GameSession{Score: 10})
+	repository.UpdateFriends(ctx, ids[1], domain.FriendsUpdate{Add: []uuid.UUID{ids[0]}})
+	repository.BlockUser(ctx, ids[2], ids[0])
+	request, _ := repository.CreateFriendRequest(ctx, ids[0], ids[1])
+
+	if touched, err := repository.Delete(ctx, ids[0]); touched != 1 || err != nil {
+		t.Fatalf("expected the user to be deleted, actual: %d touched rows, %v", touched, err)
+	}
+	if touched, _ := repository.Delete(ctx, ids[0]); touched != 0 {
+		t.Errorf("expected a second delete to touch nothing, actual: %d", touched)
+	}
+	if friends, _ := repository.ListFriends(ctx, ids[1]); len(friends) != 0 {
+		t.Errorf("expected no friends left, actual: %+v", friends)
+	}
+	if blocks, _ := repository.ListBlocks(ctx, ids[2]); len(blocks) != 0 {
+		t.Errorf("expected no blocks left, actual: %+v", blocks)
+	}
+	if found, _ := repository.FindFriendRequest(ctx, request.Id); found != nil {
+		t.Errorf("expected the friend request to be deleted, actual: %+v", found)
+	}
+	if sessions, _ := repository.ListSessions(ctx, ids[0], nil, 10); len(sessions) != 0 {
+		t.Errorf("expected no sessions left, actual: %+v", sessions)
+	}
+}
+
+func TestUserRepositoryImpl_LeaderboardRanks(t *testing.T) {
+	ctx := context.Background()
+	repository := NewUserRepository()
+	ids := createUsers(t, repository, "jake", "finn", "bubblegum", "marceline")
+	for i, score := range []uint{30, 50, 30, 10} {
+		repository.UpdateUserState(ctx, ids[i], 1, &domain.GameSession{Score: score})
+	}
+
+	board, _ := repository.Leaderboard(ctx, nil, uuid.Nil, 10, 0)
+	var ranks []string
+	for _, ranked := range board {
+		ranks = append(ranks, fmt.Sprintf("%d:%s", ranked.Rank, ranked.Name))
+	}
+	if expected := "[1:finn 2:bubblegum 2:jake 4:marceline]"; fmt.Sprint(ranks) != expected {
+		t.Errorf("expected %s, actual: %v", expected, ranks)
+	}
+
+	around, _ := repository.LeaderboardAround(ctx, nil, uuid.Nil, ids[3], 1)
+	if len(around) != 2 || around[0].Id != ids[0] || around[1].Id != ids[3] {
+		t.Errorf("expected jake and marceline around marceline, actual: %+v", around)
+	}
+}
+
+func TestUserRepositoryImpl_ConcurrentUpdates(t *testing.T) {
+	ctx := context.Background()
+	repository := NewUserRepository()
+	userId := createUsers(t, repository, "jake")[0]
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 50; i++ {
+		wg.Add(1)
+		go func(score uint) {
+			defer wg.Done()
+			repository.UpdateUserState(ctx, userId, 1, &domain.GameSession{Score: score})
+			repository.Leaderboard(ctx, nil, userId, 10, 0)
+		}(uint(i))
+	}
+	wg.Wait()
+
+	if user, _ := repository.FindUser(ctx, userId); user.Score.Int64 != 50 {
+		t.Errorf("expected the best score of 50, actual: %d", user.Score.Int64)
+	}
+	if sessions, _ := repository.ListSessions(ctx, userId, nil, 100); len(sessions) != 50 {
+		t.Errorf("expected 50 sessions, actual: %d", len(sessions))
+	}
+}
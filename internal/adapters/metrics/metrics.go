@@ -0,0 +1,128 @@
+// Package metrics exposes the Prometheus metrics of the application: the
+// HTTP requests, the database pool and the game events.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"game-project/internal/adapters/http/middleware"
+)
+
+const namespace = "game"
+
+// Metrics holds every collector in its own registry. It implements
+// application.Metrics.
+type Metrics struct {
+	registry         *prometheus.Registry
+	requests         *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	usersCreated     prometheus.Counter
+	scoreSubmissions prometheus.Counter
+	highScores       prometheus.Counter
+	friendsAdded     prometheus.Counter
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by route template, method and status code.",
+		}, []string{"route", "method", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken to answer HTTP requests, by route template and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		usersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "users_created_total",
+			Help:      "Users created.",
+		}),
+		scoreSubmissions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "score_submissions_total",
+			Help:      "Game sessions submitted.",
+		}),
+		highScores: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "high_score_improvements_total",
+			Help:      "Game sessions that beat the best score of their user.",
+		}),
+		friendsAdded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "friends_added_total",
+			Help:      "Entries added to friend lists.",
+		}),
+	}
+	m.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.usersCreated,
+		m.scoreSubmissions,
+		m.highScores,
+		m.friendsAdded,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RegisterPool exposes the statistics of the database pool.
+func (m *Metrics) RegisterPool(pool *pgxpool.Pool) {
+	m.registry.MustRegister(newPoolCollector(pool))
+}
+
+// Middleware counts and times the requests by the template of the route they
+// matched, so that paths with ids don't create a series each. It must be
+// used as a mux middleware, which only runs once a route matched.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		route := "unknown"
+		if current := mux.CurrentRoute(request); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		recorder := middleware.NewStatusRecorder(writer)
+		start := time.Now()
+
+		next.ServeHTTP(recorder, request)
+
+		m.requestDuration.WithLabelValues(route, request.Method).Observe(time.Since(start).Seconds())
+		m.requests.WithLabelValues(route, request.Method, strconv.Itoa(recorder.Status)).Inc()
+	})
+}
+
+func (m *Metrics) UserCreated() {
+	m.usersCreated.Inc()
+}
+
+func (m *Metrics) ScoreSubmitted() {
+	m.scoreSubmissions.Inc()
+}
+
+func (m *Metrics) HighScoreImproved() {
+	m.highScores.Inc()
+}
+
+func (m *Metrics) FriendsAdded(count int64) {
+	m.friendsAdded.Add(float64(count))
+}
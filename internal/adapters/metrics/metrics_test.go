@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics_Middleware(t *testing.T) {
+	m := New()
+	r := mux.NewRouter()
+	r.Use(m.Middleware)
+	r.HandleFunc("/user/{userId}", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+	r.HandleFunc("/user", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte("{}"))
+	}).Methods("POST")
+
+	for _, path := range []string{"/user/1", "/user/2"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/user", nil))
+
+	cases := []struct {
+		labels   []string
+		expected float64
+	}{
+		{labels: []string{"/user/{userId}", "GET", "404"}, expected: 2},
+		{labels: []string{"/user", "POST", "200"}, expected: 1},
+	}
+	for _, tc := range cases {
+		if actual := testutil.ToFloat64(m.requests.WithLabelValues(tc.labels...)); actual != tc.expected {
+			t.Errorf("expected %v requests for %v, actual: %v", tc.expected, tc.labels, actual)
+		}
+	}
+	if series := testutil.CollectAndCount(m.requestDuration); series != 2 {
+		t.Errorf("expected a latency series per route and method, actual: %d", series)
+	}
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m := New()
+	m.UserCreated()
+	m.FriendsAdded(3)
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	for _, line := range []string{"game_users_created_total 1", "game_friends_added_total 3"} {
+		if !strings.Contains(w.Body.String(), line) {
+			t.Errorf("expected the exposition to contain %q", line)
+		}
+	}
+}
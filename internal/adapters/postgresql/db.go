@@ -2,19 +2,21 @@ package postgresql
 
 import (
 	"context"
-	"fmt"
 	"github.com/jackc/pgx/v4/pgxpool"
 	log "github.com/sirupsen/logrus"
 )
 
-func CreatePool(host string) *pgxpool.Pool{
-	connString := fmt.Sprintf("postgresql://root:root@%s:5432/game?sslmode=disable", host)
-
+// CreatePool connects to the database. maxConns limits the size of the pool,
+// the pgx default is kept when it is 0.
+func CreatePool(connString string, maxConns int32) *pgxpool.Pool{
 	config, err := pgxpool.ParseConfig(connString)
 
 	if err != nil {
 		log.Fatal("error configuring the database: ", err)
 	}
+	if maxConns > 0 {
+		config.MaxConns = maxConns
+	}
 
 	pool, err := pgxpool.ConnectConfig(context.Background(), config)
 	if err != nil {
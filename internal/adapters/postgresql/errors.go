@@ -0,0 +1,94 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+
+	"game-project/internal/domain"
+	"game-project/internal/logging"
+)
+
+// The SQLSTATE codes the repository translates, see
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	UNIQUE_VIOLATION             = "23505"
+	FOREIGN_KEY_VIOLATION        = "23503"
+	CHECK_VIOLATION              = "23514"
+	NOT_NULL_VIOLATION           = "23502"
+	INVALID_TEXT_REPRESENTATION  = "22P02"
+	STRING_DATA_RIGHT_TRUNCATION = "22001"
+	TOO_MANY_CONNECTIONS         = "53300"
+	ADMIN_SHUTDOWN               = "57P01"
+	CRASH_SHUTDOWN               = "57P02"
+	CANNOT_CONNECT_NOW           = "57P03"
+	// Every code of the class reports a broken connection.
+	CONNECTION_EXCEPTION_CLASS = "08"
+)
+
+var errDatabaseUnavailable = domain.UnavailableError("database_unavailable", "the database is unavailable, try again later")
+
+// uniqueConflicts maps the unique constraints and indexes to the conflict
+// errors reported when an insert or update hits them.
+var uniqueConflicts = map[string]*domain.Error{
+	"user_name_key":              domain.ConflictError("name_taken", "the name is already taken"),
+	"no_duplicate_friends":       domain.ConflictError("already_friends", "the users are already friends"),
+	"friend_request_pending_idx": domain.ConflictError("friend_request_exists", "a pending friend request already exists"),
+	"user_block_pkey":            domain.ConflictError("already_blocked", "the user is already blocked"),
+}
+
+// translateError turns the errors of the driver into domain errors, so the
+// callers can tell a conflict or a missing row from a failing database.
+// Errors it does not know about are returned unchanged. The failures are
+// logged with the logger of ctx, so they carry the request they belong to.
+func translateError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.NotFoundError("not_found", "no matching record found")
+	}
+	// Timeouts and cancellations keep their context error, the callers
+	// answer them differently from an outage.
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		var netErr net.Error
+		if errors.As(err, &netErr) {
+			logging.FromContext(ctx).Warn("database unreachable, error: ", err)
+			return errDatabaseUnavailable
+		}
+		logging.FromContext(ctx).Warn("database query failed, error: ", err)
+		return err
+	}
+	logging.FromContext(ctx).Debugf("database error %s on constraint %q: %s", pgErr.Code, pgErr.ConstraintName, pgErr.Message)
+
+	switch pgErr.Code {
+	case UNIQUE_VIOLATION:
+		if conflict, ok := uniqueConflicts[pgErr.ConstraintName]; ok {
+			return conflict
+		}
+		return domain.ConflictError("conflict", "the record already exists")
+	case FOREIGN_KEY_VIOLATION:
+		return domain.NotFoundError("user_not_found", "a referenced user does not exist")
+	case CHECK_VIOLATION, NOT_NULL_VIOLATION, INVALID_TEXT_REPRESENTATION, STRING_DATA_RIGHT_TRUNCATION:
+		return domain.ValidationError("invalid_value", "a value is not valid")
+	case TOO_MANY_CONNECTIONS, ADMIN_SHUTDOWN, CRASH_SHUTDOWN, CANNOT_CONNECT_NOW:
+		logging.FromContext(ctx).Warn("database unavailable, error: ", err)
+		return errDatabaseUnavailable
+	}
+	if strings.HasPrefix(pgErr.Code, CONNECTION_EXCEPTION_CLASS) {
+		logging.FromContext(ctx).Warn("database connection failed, error: ", err)
+		return errDatabaseUnavailable
+	}
+
+	logging.FromContext(ctx).Warn("database query failed, error: ", err)
+	return err
+}
@@ -0,0 +1,119 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	log "github.com/sirupsen/logrus"
+
+	"game-project/internal/domain"
+	"game-project/internal/logging"
+)
+
+func TestTranslateError(t *testing.T) {
+	failure := errors.New("connection reset")
+	timeout := fmt.Errorf("query: %w", context.DeadlineExceeded)
+	tests := []struct {
+		name     string
+		err      error
+		wantKind error
+		wantCode string
+		wantErr  error
+	}{
+		{name: "nil", err: nil, wantErr: nil},
+		{name: "no rows", err: pgx.ErrNoRows, wantKind: domain.ErrNotFound, wantCode: "not_found"},
+		{
+			name:     "taken name",
+			err:      &pgconn.PgError{Code: UNIQUE_VIOLATION, ConstraintName: "user_name_key"},
+			wantKind: domain.ErrConflict,
+			wantCode: "name_taken",
+		},
+		{
+			name:     "wrapped pending friend request",
+			err:      fmt.Errorf("insert: %w", &pgconn.PgError{Code: UNIQUE_VIOLATION, ConstraintName: "friend_request_pending_idx"}),
+			wantKind: domain.ErrConflict,
+			wantCode: "friend_request_exists",
+		},
+		{
+			name:     "unknown unique constraint",
+			err:      &pgconn.PgError{Code: UNIQUE_VIOLATION, ConstraintName: "other_key"},
+			wantKind: domain.ErrConflict,
+			wantCode: "conflict",
+		},
+		{
+			name:     "missing referenced user",
+			err:      &pgconn.PgError{Code: FOREIGN_KEY_VIOLATION, ConstraintName: "user_block_blocked_id_fkey"},
+			wantKind: domain.ErrNotFound,
+			wantCode: "user_not_found",
+		},
+		{
+			name:     "check violation",
+			err:      &pgconn.PgError{Code: CHECK_VIOLATION, ConstraintName: "no_self_block"},
+			wantKind: domain.ErrValidation,
+			wantCode: "invalid_value",
+		},
+		{
+			name:     "too many connections",
+			err:      &pgconn.PgError{Code: TOO_MANY_CONNECTIONS},
+			wantKind: domain.ErrUnavailable,
+			wantCode: "database_unavailable",
+		},
+		{
+			name:     "connection failure",
+			err:      &pgconn.PgError{Code: "08006"},
+			wantKind: domain.ErrUnavailable,
+			wantCode: "database_unavailable",
+		},
+		{
+			name:     "unreachable server",
+			err:      fmt.Errorf("connect: %w", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}),
+			wantKind: domain.ErrUnavailable,
+			wantCode: "database_unavailable",
+		},
+		{name: "timeout", err: timeout, wantErr: timeout},
+		{name: "other pg error", err: &pgconn.PgError{Code: "42P01"}, wantErr: &pgconn.PgError{Code: "42P01"}},
+		{name: "other error", err: failure, wantErr: failure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := translateError(context.Background(), tt.err)
+			if tt.wantKind == nil {
+				if fmt.Sprint(got) != fmt.Sprint(tt.wantErr) {
+					t.Errorf("translateError() = %v, want %v", got, tt.wantErr)
+				}
+				return
+			}
+
+			var domainErr *domain.Error
+			if !errors.As(got, &domainErr) {
+				t.Fatalf("translateError() = %v, want a domain error", got)
+			}
+			if !errors.Is(got, tt.wantKind) {
+				t.Errorf("translateError() kind = %v, want %v", domainErr.Kind, tt.wantKind)
+			}
+			if domainErr.Code != tt.wantCode {
+				t.Errorf("translateError() code = %s, want %s", domainErr.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestTranslateError_LogsWithTheRequestLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger := log.New()
+	logger.SetOutput(&out)
+	ctx := logging.NewContext(context.Background(), logger.WithField("request_id", "req-1"))
+
+	translateError(ctx, &pgconn.PgError{Code: TOO_MANY_CONNECTIONS})
+	if !strings.Contains(out.String(), "request_id=req-1") {
+		t.Errorf("expected the warning to carry the request id, actual: %q", out.String())
+	}
+}
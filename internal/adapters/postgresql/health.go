@@ -0,0 +1,43 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// SchemaVersion is the migration the queries of this package are written
+// against, the number of the latest file in data/migrations.
+const SchemaVersion = 11
+
+const SELECT_SCHEMA_VERSION = `SELECT version, dirty FROM schema_migrations LIMIT 1;`
+
+// Ping checks that a connection to the database can be acquired and used.
+func Ping(pool *pgxpool.Pool) func(ctx context.Context) error {
+	return pool.Ping
+}
+
+// CheckSchema checks that the database was migrated to SchemaVersion, and
+// that the last migration did not fail halfway.
+func CheckSchema(pool *pgxpool.Pool) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var version int64
+		var dirty bool
+		err := pool.QueryRow(ctx, SELECT_SCHEMA_VERSION).Scan(&version, &dirty)
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("no migration was applied, expected version %d", SchemaVersion)
+		}
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("migration %d failed and left the schema dirty", version)
+		}
+		if version != SchemaVersion {
+			return fmt.Errorf("schema version is %d, expected %d", version, SchemaVersion)
+		}
+		return nil
+	}
+}
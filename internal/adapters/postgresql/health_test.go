@@ -0,0 +1,31 @@
+package postgresql
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	files, err := ioutil.ReadDir("../../../data/migrations")
+	if err != nil {
+		t.Fatalf("reading the migrations: %v", err)
+	}
+
+	var latest int64
+	for _, file := range files {
+		prefix := strings.SplitN(file.Name(), "_", 2)[0]
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			t.Fatalf("migration %s is not numbered: %v", file.Name(), err)
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+
+	if latest != SchemaVersion {
+		t.Errorf("the latest migration is %d but SchemaVersion is %d, update it along with the queries", latest, SchemaVersion)
+	}
+}
@@ -0,0 +1,87 @@
+package postgresql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"game-project/internal/adapters/http/ratelimit"
+	"game-project/internal/logging"
+)
+
+const (
+	// TAKE_RATE_LIMIT_TOKEN takes a token, pushing back the time the bucket is
+	// full again, unless the bucket is empty. It returns the bucket before the
+	// update and whether the token was taken.
+	TAKE_RATE_LIMIT_TOKEN = `WITH bucket AS (SELECT full_at FROM public.rate_limit_bucket WHERE key = $1),
+    taken AS (INSERT into public.rate_limit_bucket AS b (key, full_at)
+    VALUES ($1, $2::timestamptz + $3::bigint * INTERVAL '1 microsecond')
+    ON CONFLICT (key) DO UPDATE SET full_at = GREATEST(b.full_at, $2) + $3::bigint * INTERVAL '1 microsecond'
+    WHERE GREATEST(b.full_at, $2) + $3::bigint * INTERVAL '1 microsecond' <= $2::timestamptz + $4::bigint * INTERVAL '1 microsecond'
+    RETURNING full_at)
+    SELECT (SELECT full_at FROM bucket), EXISTS (SELECT 1 FROM taken);`
+	DELETE_FULL_RATE_LIMIT_BUCKETS = `DELETE FROM public.rate_limit_bucket WHERE full_at <= $1;`
+)
+
+// RateLimitStore keeps the rate limit buckets in the database, so that every
+// instance of the application shares them. Each bucket is stored as the time
+// it is full again, full buckets are deleted once a minute.
+type RateLimitStore struct {
+	pool      tracedPool
+	timeout   time.Duration
+	mu        sync.Mutex
+	nextPrune time.Time
+	now       func() time.Time
+}
+
+// NewRateLimitStore creates a store whose operations are bound by timeout,
+// or only by the caller when it is 0.
+func NewRateLimitStore(pool *pgxpool.Pool, timeout time.Duration) *RateLimitStore {
+	return &RateLimitStore{pool: tracedPool{pool: pool}, timeout: timeout, now: time.Now}
+}
+
+func (s *RateLimitStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (time.Duration, error) {
+	ctx, cancel := withTimeout(ctx, s.timeout)
+	defer cancel()
+
+	now := s.now()
+	var fullAt *time.Time
+	var taken bool
+	err := s.pool.QueryRow(ctx, TAKE_RATE_LIMIT_TOKEN, key, now, limit.Interval.Microseconds(),
+		(time.Duration(limit.Burst)*limit.Interval).Microseconds()).Scan(&fullAt, &taken)
+	if err != nil {
+		return 0, err
+	}
+	s.prune(ctx, now)
+	if taken {
+		return 0, nil
+	}
+
+	var wait time.Duration
+	if fullAt != nil {
+		_, wait = limit.Take(*fullAt, now)
+	}
+	if wait <= 0 {
+		// The bucket was refilled by a concurrent request in between.
+		wait = limit.Interval
+	}
+	return wait, nil
+}
+
+// prune deletes the full buckets once a minute. A failure is only logged,
+// the buckets are deleted by the next prune.
+func (s *RateLimitStore) prune(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Before(s.nextPrune) {
+		s.mu.Unlock()
+		return
+	}
+	s.nextPrune = now.Add(time.Minute)
+	s.mu.Unlock()
+
+	if _, err := s.pool.Exec(ctx, DELETE_FULL_RATE_LIMIT_BUCKETS, now); err != nil {
+		logging.FromContext(ctx).Warn("error pruning the rate limit buckets: ", err)
+	}
+}
@@ -0,0 +1,60 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"game-project/internal/adapters/http/ratelimit"
+)
+
+const TRUNCATE_RATE_LIMIT_BUCKETS = `TRUNCATE public.rate_limit_bucket;`
+
+func TestRateLimitStore_Take(t *testing.T) {
+	pool := connectTestDatabase(t)
+	if _, err := pool.Exec(context.Background(), TRUNCATE_RATE_LIMIT_BUCKETS); err != nil {
+		t.Fatalf("emptying the buckets: %v", err)
+	}
+
+	now := time.Now().Truncate(time.Microsecond)
+	store := NewRateLimitStore(pool, 5*time.Second)
+	store.now = func() time.Time { return now }
+	limit := ratelimit.Limit{Interval: time.Second, Burst: 3}
+	take := func(key string) time.Duration {
+		wait, err := store.Take(context.Background(), key, limit)
+		if err != nil {
+			t.Fatalf("taking a token: %v", err)
+		}
+		return wait
+	}
+
+	for i := 0; i < 3; i++ {
+		if wait := take("k"); wait != 0 {
+			t.Fatalf("request %d should fit in the burst, waiting %s instead", i+1, wait)
+		}
+	}
+	if wait := take("k"); wait != time.Second {
+		t.Fatalf("an empty bucket should wait for the next token, waiting %s instead", wait)
+	}
+	if wait := take("other"); wait != 0 {
+		t.Fatalf("buckets should not be shared between keys, waiting %s instead", wait)
+	}
+
+	now = now.Add(1500 * time.Millisecond)
+	if wait := take("k"); wait != 0 {
+		t.Fatalf("a refilled token should be taken, waiting %s instead", wait)
+	}
+	if wait := take("k"); wait != 500*time.Millisecond {
+		t.Fatalf("the wait should count from the last refill, waiting %s instead", wait)
+	}
+
+	now = now.Add(time.Hour)
+	take("k")
+	var buckets int
+	if err := pool.QueryRow(context.Background(), `SELECT COUNT(*) FROM public.rate_limit_bucket;`).Scan(&buckets); err != nil {
+		t.Fatalf("counting the buckets: %v", err)
+	}
+	if buckets != 1 {
+		t.Errorf("full buckets should be pruned, %d buckets kept", buckets)
+	}
+}
@@ -0,0 +1,129 @@
+package postgresql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("game-project/internal/adapters/postgresql")
+
+// tracedPool records a span for every statement sent through the pool or
+// the transactions it begins. A query's span ends once its rows are closed
+// or its row is scanned.
+type tracedPool struct {
+	pool *pgxpool.Pool
+}
+
+func (p tracedPool) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	ctx, span := startStatement(ctx, sql)
+	defer span.End()
+
+	tag, err := p.pool.Exec(ctx, sql, args...)
+	return tag, endStatement(span, err)
+}
+
+func (p tracedPool) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	ctx, span := startStatement(ctx, sql)
+	rows, err := p.pool.Query(ctx, sql, args...)
+	if err != nil {
+		endStatement(span, err)
+		span.End()
+		return nil, err
+	}
+	return tracedRows{Rows: rows, span: span}, nil
+}
+
+func (p tracedPool) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	ctx, span := startStatement(ctx, sql)
+	return tracedRow{row: p.pool.QueryRow(ctx, sql, args...), span: span}
+}
+
+func (p tracedPool) Begin(ctx context.Context) (tracedTx, error) {
+	tx, err := p.pool.Begin(ctx)
+	return tracedTx{tx: tx}, err
+}
+
+type tracedTx struct {
+	tx pgx.Tx
+}
+
+func (t tracedTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	ctx, span := startStatement(ctx, sql)
+	defer span.End()
+
+	tag, err := t.tx.Exec(ctx, sql, args...)
+	return tag, endStatement(span, err)
+}
+
+func (t tracedTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	ctx, span := startStatement(ctx, sql)
+	return tracedRow{row: t.tx.QueryRow(ctx, sql, args...), span: span}
+}
+
+func (t tracedTx) Commit(ctx context.Context) error {
+	return t.tx.Commit(ctx)
+}
+
+func (t tracedTx) Rollback(ctx context.Context) error {
+	return t.tx.Rollback(ctx)
+}
+
+type tracedRows struct {
+	pgx.Rows
+	span trace.Span
+}
+
+func (r tracedRows) Close() {
+	r.Rows.Close()
+	endStatement(r.span, r.Rows.Err())
+	r.span.End()
+}
+
+type tracedRow struct {
+	row  pgx.Row
+	span trace.Span
+}
+
+func (r tracedRow) Scan(dest ...interface{}) error {
+	defer r.span.End()
+
+	err := r.row.Scan(dest...)
+	if err == pgx.ErrNoRows {
+		// An empty result is an answer, not a failure of the statement.
+		return err
+	}
+	return endStatement(r.span, err)
+}
+
+// startStatement names the span after the SQL command, the whole statement
+// is recorded as an attribute. Arguments are left out, they may hold secrets.
+func startStatement(ctx context.Context, sql string) (context.Context, trace.Span) {
+	operation := "SQL"
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+	return tracer.Start(ctx, "postgresql "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBStatementKey.String(sql),
+			semconv.DBOperationKey.String(operation),
+		),
+	)
+}
+
+func endStatement(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
@@ -2,126 +2,693 @@ package postgresql
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
-	log "github.com/sirupsen/logrus"
 
 	"game-project/internal/domain"
 )
 
 const (
-	INSERT_USER = `INSERT into game.public.user (id, name) VALUES ($1, $2);`
-	UPDATE_USER = `UPDATE game.public.user SET games_played = $1, score = GREATEST(score, $2) WHERE id = $3;`
-	SELECT_USER = `SELECT id, name, games_played, score FROM game.public.user WHERE id = $1;`
-	INSERT_FRIENDS = `INSERT into game.public.user_friends (user_id, friend_id) VALUES %s ON CONFLICT DO NOTHING;`
-	FRIENDS_VALUES = `?, ?`
-	SELECT_FRIENDS = `SELECT id, name, score FROM game.public.user AS u INNER JOIN game.public.user_friends AS f ON
-    f.friend_id = u.id WHERE f.user_id = $1;`
-	LIST_USER = `SELECT id, name, score FROM game.public.user;`
+	INSERT_USER        = `INSERT into public.user (id, name, secret_hash) VALUES ($1, $2, $3);`
+	UPDATE_USER_SECRET = `UPDATE public.user SET secret_hash = $2 WHERE id = $1;`
+	UPDATE_USER        = `UPDATE public.user SET games_played = $1, score = GREATEST(score, $2) WHERE id = $3;`
+	SELECT_USER        = `SELECT id, name, games_played, score, secret_hash, country, avatar_url, bio, name_changed_at
+    FROM public.user WHERE id = $1;`
+	INSERT_NAME_HISTORY = `INSERT into public.user_name_history (user_id, name)
+    SELECT id, name FROM public.user WHERE id = $1 AND name <> $2;`
+	UPDATE_PROFILE = `UPDATE public.user SET name = $2, country = $3, avatar_url = $4, bio = $5,
+    name_changed_at = CASE WHEN name <> $2 THEN now() ELSE name_changed_at END WHERE id = $1
+    RETURNING name_changed_at;`
+	SELECT_NAME_HISTORY = `SELECT name, changed_at FROM public.user_name_history
+    WHERE user_id = $1 ORDER BY changed_at DESC;`
+	DELETE_USER    = `DELETE FROM public.user WHERE id = $1;`
+	INSERT_FRIENDS = `WITH pairs AS (SELECT v.user_id, v.friend_id FROM (VALUES %s) AS v (user_id, friend_id)
+    WHERE NOT EXISTS (SELECT 1 FROM public.user_block AS b
+    WHERE (b.user_id = v.user_id AND b.blocked_id = v.friend_id) OR (b.user_id = v.friend_id AND b.blocked_id = v.user_id))),
+    added AS (INSERT into public.user_friends (user_id, friend_id) SELECT user_id, friend_id FROM pairs
+    ON CONFLICT DO NOTHING RETURNING friend_id),
+    reverse AS (INSERT into public.user_friends (user_id, friend_id) SELECT friend_id, user_id FROM pairs
+    ON CONFLICT DO NOTHING)
+    SELECT COUNT(*) FROM added;`
+	FRIENDS_VALUES = `?::uuid, ?::uuid`
+	DELETE_FRIENDS = `WITH removed AS (DELETE FROM public.user_friends
+    WHERE user_id = $1 AND friend_id = ANY($2) RETURNING friend_id),
+    reverse AS (DELETE FROM public.user_friends WHERE friend_id = $1 AND user_id = ANY($2))
+    SELECT COUNT(*) FROM removed;`
+	DELETE_FRIENDS_EXCEPT = `WITH removed AS (DELETE FROM public.user_friends
+    WHERE user_id = $1 AND NOT (friend_id = ANY($2)) RETURNING friend_id),
+    reverse AS (DELETE FROM public.user_friends WHERE friend_id = $1 AND user_id IN (SELECT friend_id FROM removed))
+    SELECT COUNT(*) FROM removed;`
+	SELECT_FRIENDS = `SELECT id, name, score FROM public.user AS u INNER JOIN public.user_friends AS f ON
+    f.friend_id = u.id WHERE f.user_id = $1 AND NOT EXISTS (SELECT 1 FROM public.user_block AS b
+    WHERE b.user_id = $1 AND b.blocked_id = u.id);`
+	INSERT_BLOCK              = `INSERT into public.user_block (user_id, blocked_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;`
+	DELETE_BLOCKED_FRIENDSHIP = `DELETE FROM public.user_friends
+    WHERE (user_id = $1 AND friend_id = $2) OR (user_id = $2 AND friend_id = $1);`
+	CANCEL_BLOCKED_FRIEND_REQUESTS = `UPDATE public.friend_request SET status = 'cancelled', updated_at = now()
+    WHERE status = 'pending' AND ((from_user_id = $1 AND to_user_id = $2) OR (from_user_id = $2 AND to_user_id = $1));`
+	DELETE_BLOCK  = `DELETE FROM public.user_block WHERE user_id = $1 AND blocked_id = $2;`
+	SELECT_BLOCKS = `SELECT u.id, u.name FROM public.user AS u INNER JOIN public.user_block AS b
+    ON b.blocked_id = u.id WHERE b.user_id = $1 ORDER BY b.created_at DESC;`
+	SELECT_EXISTING_USERS = `SELECT id FROM public.user WHERE id = ANY($1);`
+	SELECT_BLOCKED_AMONG  = `SELECT CASE WHEN user_id = $1 THEN blocked_id ELSE user_id END FROM public.user_block
+    WHERE (user_id = $1 AND blocked_id = ANY($2)) OR (blocked_id = $1 AND user_id = ANY($2));`
+	LIST_USERS_FILTER  = `COALESCE(score, 0) >= $1 AND COALESCE(games_played, 0) >= $2`
+	LIST_USERS_BY_NAME = `SELECT id, name, games_played, score, created_at FROM public.user
+    WHERE ` + LIST_USERS_FILTER + ` AND ($3::text IS NULL OR (name, id) > ($3, $4::uuid))
+    ORDER BY name, id LIMIT $5;`
+	LIST_USERS_BY_SCORE = `SELECT id, name, games_played, score, created_at FROM public.user
+    WHERE ` + LIST_USERS_FILTER + ` AND ($3::bigint IS NULL OR (COALESCE(score, 0), id) < ($3, $4::uuid))
+    ORDER BY COALESCE(score, 0) DESC, id DESC LIMIT $5;`
+	LIST_USERS_BY_CREATED = `SELECT id, name, games_played, score, created_at FROM public.user
+    WHERE ` + LIST_USERS_FILTER + ` AND ($3::timestamptz IS NULL OR (created_at, id) < ($3, $4::uuid))
+    ORDER BY created_at DESC, id DESC LIMIT $5;`
+	SEARCH_USERS = `SELECT id, name, games_played, score FROM (
+    SELECT id, name, games_played, score, lower(name) LIKE $2 AS prefix, similarity(lower(name), $1) AS similarity
+    FROM public.user WHERE lower(name) LIKE $2 OR lower(name) % $1) AS matches
+    WHERE NOT EXISTS (SELECT 1 FROM public.user_block AS b WHERE b.user_id = $3 AND b.blocked_id = matches.id)
+    ORDER BY prefix DESC, similarity DESC, name LIMIT $4 OFFSET $5;`
+	INSERT_FRIEND_REQUEST = `INSERT into public.friend_request (id, from_user_id, to_user_id)
+    VALUES ($1, $2, $3) RETURNING status, created_at, updated_at;`
+	SELECT_FRIEND_REQUEST = `SELECT id, from_user_id, to_user_id, status, created_at, updated_at
+    FROM public.friend_request WHERE id = $1;`
+	SELECT_INCOMING_FRIEND_REQUESTS = `SELECT id, from_user_id, to_user_id, status, created_at, updated_at
+    FROM public.friend_request WHERE to_user_id = $1 AND status = 'pending' ORDER BY created_at DESC;`
+	SELECT_OUTGOING_FRIEND_REQUESTS = `SELECT id, from_user_id, to_user_id, status, created_at, updated_at
+    FROM public.friend_request WHERE from_user_id = $1 AND status = 'pending' ORDER BY created_at DESC;`
+	UPDATE_FRIEND_REQUEST_STATUS = `UPDATE public.friend_request SET status = $1, updated_at = now()
+    WHERE id = $2 AND status = 'pending' RETURNING from_user_id, to_user_id;`
+	INSERT_MUTUAL_FRIENDS = `INSERT into public.user_friends (user_id, friend_id) VALUES ($1, $2), ($2, $1)
+    ON CONFLICT DO NOTHING;`
+	INSERT_GAME_SESSION = `INSERT into public.game_session (user_id, score, duration_ms, metadata)
+    VALUES ($1, $2, $3, $4) RETURNING id, submitted_at;`
+	SELECT_GAME_SESSIONS = `SELECT id, user_id, score, duration_ms, metadata, submitted_at FROM public.game_session
+    WHERE user_id = $1 AND ($2::timestamptz IS NULL OR (submitted_at, id) < ($2, $3))
+    ORDER BY submitted_at DESC, id DESC LIMIT $4;`
+	PERIOD_SCORE = `CASE WHEN $1::timestamptz IS NULL THEN u.score ELSE (SELECT MAX(s.score)
+    FROM public.game_session AS s WHERE s.user_id = u.id AND s.submitted_at >= $1) END`
+	PERIOD_BEST_SCORES = `SELECT user_id, MAX(score) AS score FROM public.game_session
+    WHERE submitted_at >= $1 GROUP BY user_id`
+	RANKED_USERS = `SELECT id, name, score,
+    RANK() OVER (ORDER BY COALESCE(score, 0) DESC) AS rank,
+    ROW_NUMBER() OVER (ORDER BY COALESCE(score, 0) DESC, name ASC) AS position
+    FROM (SELECT u.id, u.name, u.score FROM public.user AS u WHERE $1::timestamptz IS NULL
+    UNION ALL SELECT u.id, u.name, s.score FROM (` + PERIOD_BEST_SCORES + `) AS s
+    INNER JOIN public.user AS u ON u.id = s.user_id) AS scores`
+	SELECT_LEADERBOARD = `SELECT id, name, score, rank FROM (` + RANKED_USERS + `) AS r
+    WHERE NOT EXISTS (SELECT 1 FROM public.user_block AS b WHERE b.user_id = $4 AND b.blocked_id = r.id)
+    ORDER BY position LIMIT $2 OFFSET $3;`
+	SELECT_LEADERBOARD_AROUND = `WITH ranked AS (` + RANKED_USERS + `)
+    SELECT r.id, r.name, r.score, r.rank FROM ranked AS r INNER JOIN ranked AS me ON me.id = $2
+    WHERE r.position BETWEEN me.position - $3 AND me.position + $3
+    AND (r.id = $2 OR NOT EXISTS (SELECT 1 FROM public.user_block AS b WHERE b.user_id = $4 AND b.blocked_id = r.id))
+    ORDER BY r.position;`
+	SELECT_FRIENDS_LEADERBOARD = `WITH members AS (SELECT u.id, u.name, ` + PERIOD_SCORE + ` AS score
+    FROM public.user AS u WHERE u.id = $2
+    OR u.id IN (SELECT friend_id FROM public.user_friends AS f WHERE f.user_id = $2
+    AND NOT EXISTS (SELECT 1 FROM public.user_block AS b WHERE b.user_id = $2 AND b.blocked_id = f.friend_id)))
+    SELECT m.id, m.name, m.score, RANK() OVER (ORDER BY COALESCE(m.score, 0) DESC) AS rank,
+    COALESCE(m.score, 0) - COALESCE((SELECT score FROM members WHERE id = $2), 0) AS delta
+    FROM members AS m ORDER BY rank, m.name;`
 )
 
+// likeEscaper escapes the wildcards of user input in LIKE patterns, using
+// the default backslash escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 type UserRepositoryImpl struct {
-	pool *pgxpool.Pool
+	pool     tracedPool
+	timeouts Timeouts
+}
+
+// Timeouts bound how long a single repository operation may take, on top of
+// the deadline of the caller's context. An operation is only bound by the
+// caller when its timeout is 0.
+type Timeouts struct {
+	Read  time.Duration
+	Write time.Duration
 }
 
-func NewUserRepository(pool *pgxpool.Pool) *UserRepositoryImpl{
-	return &UserRepositoryImpl{pool: pool}
+func NewUserRepository(pool *pgxpool.Pool, timeouts Timeouts) *UserRepositoryImpl {
+	return &UserRepositoryImpl{pool: tracedPool{pool: pool}, timeouts: timeouts}
 }
 
-func (r *UserRepositoryImpl) List() []*domain.User {
+func (r *UserRepositoryImpl) List(ctx context.Context, listing domain.UserListing) ([]*domain.User, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
 	var usrLst []*domain.User
-	rows, err := r.pool.Query(context.Background(), LIST_USER)
+	var after, afterId interface{}
+	if listing.After != nil {
+		afterId = listing.After.Id
+		switch listing.Sort {
+		case domain.UserSortScore:
+			after = listing.After.Score
+		case domain.UserSortCreated:
+			after = listing.After.CreatedAt
+		default:
+			after = listing.After.Name
+		}
+	}
+	statement := LIST_USERS_BY_NAME
+	switch listing.Sort {
+	case domain.UserSortScore:
+		statement = LIST_USERS_BY_SCORE
+	case domain.UserSortCreated:
+		statement = LIST_USERS_BY_CREATED
+	}
 
+	rows, err := r.pool.Query(ctx, statement,
+		listing.Filter.MinScore, listing.Filter.MinGamesPlayed, after, afterId, listing.Limit)
+	if err != nil {
+		return nil, translateError(ctx, err)
+	}
 	defer rows.Close()
+
+	for rows.Next() {
+		userProj := domain.User{}
+		err = rows.Scan(&userProj.Id, &userProj.Name, &userProj.GamesPlayed, &userProj.Score, &userProj.CreatedAt)
+		if err != nil {
+			return nil, translateError(ctx, err)
+		}
+
+		usrLst = append(usrLst, &userProj)
+	}
+
+	return usrLst, translateError(ctx, rows.Err())
+}
+
+func (r *UserRepositoryImpl) Search(ctx context.Context, term string, viewerId uuid.UUID, limit uint, offset uint) ([]*domain.User, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+	var usrLst []*domain.User
+	term = strings.ToLower(term)
+	prefix := likeEscaper.Replace(term) + "%"
+	rows, err := r.pool.Query(ctx, SEARCH_USERS, term, prefix, viewerId, limit, offset)
 	if err != nil {
-		log.Warn("Could not retrieve friends from db, error: ", err)
-		return nil
+		return nil, translateError(ctx, err)
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		userProj := domain.User{}
-		err = rows.Scan(&userProj.Id, &userProj.Name, &userProj.Score)
+		err = rows.Scan(&userProj.Id, &userProj.Name, &userProj.GamesPlayed, &userProj.Score)
 		if err != nil {
-			log.Warn(err)
+			return nil, translateError(ctx, err)
 		}
 
 		usrLst = append(usrLst, &userProj)
 	}
 
-	return usrLst
+	return usrLst, translateError(ctx, rows.Err())
 }
 
-func (r *UserRepositoryImpl) UpdateFriends(userId uuid.UUID, friendLst []uuid.UUID) (int64, error) {
-	st := getBulkInsertSQL(INSERT_FRIENDS, FRIENDS_VALUES, len(friendLst))
-	values := make([]interface{}, 0, len(friendLst))
-	for _, friendId := range friendLst {
-		values = append(values, userId, friendId)
+func (r *UserRepositoryImpl) UpdateFriends(ctx context.Context, userId uuid.UUID, update domain.FriendsUpdate) (int64, int64, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, translateError(ctx, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var removed int64
+	if update.Replace {
+		err = tx.QueryRow(ctx, DELETE_FRIENDS_EXCEPT, userId, uuidStrings(update.Add)).Scan(&removed)
+	} else if len(update.Remove) > 0 {
+		err = tx.QueryRow(ctx, DELETE_FRIENDS, userId, uuidStrings(update.Remove)).Scan(&removed)
+	}
+	if err != nil {
+		return 0, 0, translateError(ctx, err)
 	}
 
-	exec, err := r.pool.Exec(context.Background(), st, values...)
+	var added int64
+	if len(update.Add) > 0 {
+		st := getBulkInsertSQL(INSERT_FRIENDS, FRIENDS_VALUES, len(update.Add))
+		values := make([]interface{}, 0, 2*len(update.Add))
+		for _, friendId := range update.Add {
+			values = append(values, userId, friendId)
+		}
 
-	return exec.RowsAffected(), err
+		err = tx.QueryRow(ctx, st, values...).Scan(&added)
+		if err != nil {
+			return 0, 0, translateError(ctx, err)
+		}
+	}
+
+	return added, removed, translateError(ctx, tx.Commit(ctx))
 }
 
-func (r *UserRepositoryImpl) Create(uName string) (*domain.User, error) {
+func (r *UserRepositoryImpl) Create(ctx context.Context, uName string, secretHash []byte) (*domain.User, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
 	uuid, _ := uuid.NewV4()
 	user := &domain.User{
-		Id: uuid ,
-		Name: uName,
+		Id:         uuid,
+		Name:       uName,
+		SecretHash: secretHash,
 	}
 
-	_, err := r.pool.Exec(context.Background(), INSERT_USER, user.Id, user.Name)
+	_, err := r.pool.Exec(ctx, INSERT_USER, user.Id, user.Name, user.SecretHash)
 	if err != nil {
 
-		return nil, err
+		return nil, translateError(ctx, err)
 	}
 
 	return user, err
 }
 
-func (r *UserRepositoryImpl) UpdateUserState(userId uuid.UUID, gamesPlayed uint8, score uint) error {
-	_, err := r.pool.Exec(context.Background(), UPDATE_USER, gamesPlayed, score, userId)
+func (r *UserRepositoryImpl) UpdateUserState(ctx context.Context, userId uuid.UUID, gamesPlayed uint8, session *domain.GameSession) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return translateError(ctx, err)
+	}
+	defer tx.Rollback(ctx)
 
-	return err
+	_, err = tx.Exec(ctx, UPDATE_USER, gamesPlayed, session.Score, userId)
+	if err != nil {
+		return translateError(ctx, err)
+	}
+	var metadata interface{}
+	if len(session.Metadata) > 0 {
+		metadata = session.Metadata
+	}
+	err = tx.QueryRow(ctx, INSERT_GAME_SESSION, userId, session.Score, session.Duration.Milliseconds(), metadata).
+		Scan(&session.Id, &session.SubmittedAt)
+	if err != nil {
+		return translateError(ctx, err)
+	}
+	session.UserId = userId
+
+	return translateError(ctx, tx.Commit(ctx))
 }
 
-func (r *UserRepositoryImpl) FindUser(userId uuid.UUID) *domain.User {
+func (r *UserRepositoryImpl) FindUser(ctx context.Context, userId uuid.UUID) (*domain.User, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
 	var user domain.User
-	row := r.pool.QueryRow(context.Background(), SELECT_USER, userId)
+	row := r.pool.QueryRow(ctx, SELECT_USER, userId)
+
+	err := row.Scan(&user.Id, &user.Name, &user.GamesPlayed, &user.Score, &user.SecretHash,
+		&user.Country, &user.AvatarUrl, &user.Bio, &user.NameChangedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, translateError(ctx, err)
+	}
 
-	err := row.Scan(&user.Id, &user.Name, &user.GamesPlayed, &user.Score)
+	return &user, nil
+}
+
+func (r *UserRepositoryImpl) ExistingUsers(ctx context.Context, userIds []uuid.UUID) ([]uuid.UUID, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+	var existingLst []uuid.UUID
+	rows, err := r.pool.Query(ctx, SELECT_EXISTING_USERS, uuidStrings(userIds))
 	if err != nil {
-		log.Warnf("User with id %s not found", userId)
-		return nil
+		return nil, translateError(ctx, err)
 	}
+	defer rows.Close()
 
-	return &user
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, translateError(ctx, err)
+		}
+
+		existingLst = append(existingLst, id)
+	}
+
+	return existingLst, translateError(ctx, rows.Err())
 }
 
-func (r *UserRepositoryImpl) ListFriends(userId uuid.UUID) []*domain.User {
-	var friendList []*domain.User
-	rows, err := r.pool.Query(context.Background(), SELECT_FRIENDS, userId)
+func (r *UserRepositoryImpl) UpdateProfile(ctx context.Context, user *domain.User) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return translateError(ctx, err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, INSERT_NAME_HISTORY, user.Id, user.Name)
+	if err != nil {
+		return translateError(ctx, err)
+	}
+	err = tx.QueryRow(ctx, UPDATE_PROFILE, user.Id, user.Name, user.Country, user.AvatarUrl, user.Bio).
+		Scan(&user.NameChangedAt)
+	if err != nil {
+		return translateError(ctx, err)
+	}
+
+	return translateError(ctx, tx.Commit(ctx))
+}
+
+func (r *UserRepositoryImpl) ListNameHistory(ctx context.Context, userId uuid.UUID) ([]*domain.NameChange, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+	var historyLst []*domain.NameChange
+	rows, err := r.pool.Query(ctx, SELECT_NAME_HISTORY, userId)
+	if err != nil {
+		return nil, translateError(ctx, err)
+	}
 	defer rows.Close()
+
+	for rows.Next() {
+		change := domain.NameChange{}
+		err = rows.Scan(&change.Name, &change.ChangedAt)
+		if err != nil {
+			return nil, translateError(ctx, err)
+		}
+
+		historyLst = append(historyLst, &change)
+	}
+
+	return historyLst, translateError(ctx, rows.Err())
+}
+
+func (r *UserRepositoryImpl) UpdateSecret(ctx context.Context, userId uuid.UUID, secretHash []byte) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+	exec, err := r.pool.Exec(ctx, UPDATE_USER_SECRET, userId, secretHash)
+
+	return exec.RowsAffected(), translateError(ctx, err)
+}
+
+func (r *UserRepositoryImpl) Delete(ctx context.Context, userId uuid.UUID) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+	exec, err := r.pool.Exec(ctx, DELETE_USER, userId)
+
+	return exec.RowsAffected(), translateError(ctx, err)
+}
+
+func (r *UserRepositoryImpl) ListFriends(ctx context.Context, userId uuid.UUID) ([]*domain.User, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+	var friendList []*domain.User
+	rows, err := r.pool.Query(ctx, SELECT_FRIENDS, userId)
 	if err != nil {
-		log.Warn("Could not retrieve friends from db, error: ", err)
-		return nil
+		return nil, translateError(ctx, err)
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		usr := domain.User{}
 		err = rows.Scan(&usr.Id, &usr.Name, &usr.Score)
 		if err != nil {
-			log.Warn(err)
+			return nil, translateError(ctx, err)
 		}
 
 		friendList = append(friendList, &usr)
 	}
 
-	return friendList
+	return friendList, translateError(ctx, rows.Err())
+}
+
+func (r *UserRepositoryImpl) CreateFriendRequest(ctx context.Context, fromUserId uuid.UUID, toUserId uuid.UUID) (*domain.FriendRequest, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+	id, _ := uuid.NewV4()
+	request := domain.FriendRequest{Id: id, FromUserId: fromUserId, ToUserId: toUserId}
+
+	err := r.pool.QueryRow(ctx, INSERT_FRIEND_REQUEST, request.Id, request.FromUserId, request.ToUserId).
+		Scan(&request.Status, &request.CreatedAt, &request.UpdatedAt)
+	if err != nil {
+		return nil, translateError(ctx, err)
+	}
+
+	return &request, nil
+}
+
+func (r *UserRepositoryImpl) FindFriendRequest(ctx context.Context, requestId uuid.UUID) (*domain.FriendRequest, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+	var request domain.FriendRequest
+	row := r.pool.QueryRow(ctx, SELECT_FRIEND_REQUEST, requestId)
+
+	err := row.Scan(&request.Id, &request.FromUserId, &request.ToUserId, &request.Status, &request.CreatedAt, &request.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, translateError(ctx, err)
+	}
+
+	return &request, nil
+}
+
+func (r *UserRepositoryImpl) ListFriendRequests(ctx context.Context, userId uuid.UUID, direction domain.FriendRequestDirection) ([]*domain.FriendRequest, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+	var requestLst []*domain.FriendRequest
+	st := SELECT_INCOMING_FRIEND_REQUESTS
+	if direction == domain.FriendRequestOutgoing {
+		st = SELECT_OUTGOING_FRIEND_REQUESTS
+	}
+	rows, err := r.pool.Query(ctx, st, userId)
+	if err != nil {
+		return nil, translateError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		request := domain.FriendRequest{}
+		err = rows.Scan(&request.Id, &request.FromUserId, &request.ToUserId, &request.Status, &request.CreatedAt, &request.UpdatedAt)
+		if err != nil {
+			return nil, translateError(ctx, err)
+		}
+
+		requestLst = append(requestLst, &request)
+	}
+
+	return requestLst, translateError(ctx, rows.Err())
+}
+
+func (r *UserRepositoryImpl) ResolveFriendRequest(ctx context.Context, requestId uuid.UUID, status domain.FriendRequestStatus) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, translateError(ctx, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var fromUserId, toUserId uuid.UUID
+	err = tx.QueryRow(ctx, UPDATE_FRIEND_REQUEST_STATUS, status, requestId).Scan(&fromUserId, &toUserId)
+	if err == pgx.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, translateError(ctx, err)
+	}
+	if status == domain.FriendRequestAccepted {
+		_, err = tx.Exec(ctx, INSERT_MUTUAL_FRIENDS, fromUserId, toUserId)
+		if err != nil {
+			return 0, translateError(ctx, err)
+		}
+	}
+
+	return 1, translateError(ctx, tx.Commit(ctx))
+}
+
+func (r *UserRepositoryImpl) BlockUser(ctx context.Context, userId uuid.UUID, blockedId uuid.UUID) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, translateError(ctx, err)
+	}
+	defer tx.Rollback(ctx)
+
+	exec, err := tx.Exec(ctx, INSERT_BLOCK, userId, blockedId)
+	if err != nil {
+		return 0, translateError(ctx, err)
+	}
+	_, err = tx.Exec(ctx, DELETE_BLOCKED_FRIENDSHIP, userId, blockedId)
+	if err != nil {
+		return 0, translateError(ctx, err)
+	}
+	_, err = tx.Exec(ctx, CANCEL_BLOCKED_FRIEND_REQUESTS, userId, blockedId)
+	if err != nil {
+		return 0, translateError(ctx, err)
+	}
+
+	return exec.RowsAffected(), translateError(ctx, tx.Commit(ctx))
+}
+
+func (r *UserRepositoryImpl) UnblockUser(ctx context.Context, userId uuid.UUID, blockedId uuid.UUID) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+	exec, err := r.pool.Exec(ctx, DELETE_BLOCK, userId, blockedId)
+
+	return exec.RowsAffected(), translateError(ctx, err)
+}
+
+func (r *UserRepositoryImpl) ListBlocks(ctx context.Context, userId uuid.UUID) ([]*domain.User, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+	var blockedLst []*domain.User
+	rows, err := r.pool.Query(ctx, SELECT_BLOCKS, userId)
+	if err != nil {
+		return nil, translateError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		usr := domain.User{}
+		err = rows.Scan(&usr.Id, &usr.Name)
+		if err != nil {
+			return nil, translateError(ctx, err)
+		}
+
+		blockedLst = append(blockedLst, &usr)
+	}
+
+	return blockedLst, translateError(ctx, rows.Err())
+}
+
+func (r *UserRepositoryImpl) BlockedAmong(ctx context.Context, userId uuid.UUID, otherIds []uuid.UUID) ([]uuid.UUID, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+	var blockedLst []uuid.UUID
+	rows, err := r.pool.Query(ctx, SELECT_BLOCKED_AMONG, userId, uuidStrings(otherIds))
+	if err != nil {
+		return nil, translateError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, translateError(ctx, err)
+		}
+
+		blockedLst = append(blockedLst, id)
+	}
+
+	return blockedLst, translateError(ctx, rows.Err())
+}
+
+func (r *UserRepositoryImpl) ListSessions(ctx context.Context, userId uuid.UUID, cursor *domain.SessionCursor, limit uint) ([]*domain.GameSession, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+	var sessionLst []*domain.GameSession
+	var before *time.Time
+	var beforeId int64
+	if cursor != nil {
+		before, beforeId = &cursor.SubmittedAt, cursor.Id
+	}
+	rows, err := r.pool.Query(ctx, SELECT_GAME_SESSIONS, userId, before, beforeId, limit)
+	if err != nil {
+		return nil, translateError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		session := domain.GameSession{}
+		var durationMs sql.NullInt64
+		var metadata []byte
+		err = rows.Scan(&session.Id, &session.UserId, &session.Score, &durationMs, &metadata, &session.SubmittedAt)
+		if err != nil {
+			return nil, translateError(ctx, err)
+		}
+		session.Duration = time.Duration(durationMs.Int64) * time.Millisecond
+		session.Metadata = metadata
+
+		sessionLst = append(sessionLst, &session)
+	}
+
+	return sessionLst, translateError(ctx, rows.Err())
+}
+
+func (r *UserRepositoryImpl) Leaderboard(ctx context.Context, since *time.Time, viewerId uuid.UUID, limit uint, offset uint) ([]*domain.RankedUser, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+	rows, err := r.pool.Query(ctx, SELECT_LEADERBOARD, since, limit, offset, viewerId)
+	if err != nil {
+		return nil, translateError(ctx, err)
+	}
+	defer rows.Close()
+
+	return scanRankedUsers(ctx, rows)
+}
+
+func (r *UserRepositoryImpl) LeaderboardAround(ctx context.Context, since *time.Time, viewerId uuid.UUID, userId uuid.UUID, radius uint) ([]*domain.RankedUser, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+	rows, err := r.pool.Query(ctx, SELECT_LEADERBOARD_AROUND, since, userId, radius, viewerId)
+	if err != nil {
+		return nil, translateError(ctx, err)
+	}
+	defer rows.Close()
+
+	return scanRankedUsers(ctx, rows)
+}
+
+func (r *UserRepositoryImpl) FriendsLeaderboard(ctx context.Context, since *time.Time, userId uuid.UUID) ([]*domain.RankedUser, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+	var rankedLst []*domain.RankedUser
+	rows, err := r.pool.Query(ctx, SELECT_FRIENDS_LEADERBOARD, since, userId)
+	if err != nil {
+		return nil, translateError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		usr := domain.RankedUser{}
+		err = rows.Scan(&usr.Id, &usr.Name, &usr.Score, &usr.Rank, &usr.Delta)
+		if err != nil {
+			return nil, translateError(ctx, err)
+		}
+
+		rankedLst = append(rankedLst, &usr)
+	}
+
+	return rankedLst, translateError(ctx, rows.Err())
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func scanRankedUsers(ctx context.Context, rows pgx.Rows) ([]*domain.RankedUser, error) {
+	var rankedLst []*domain.RankedUser
+	for rows.Next() {
+		usr := domain.RankedUser{}
+		err := rows.Scan(&usr.Id, &usr.Name, &usr.Score, &usr.Rank)
+		if err != nil {
+			return nil, translateError(ctx, err)
+		}
+
+		rankedLst = append(rankedLst, &usr)
+	}
+
+	return rankedLst, translateError(ctx, rows.Err())
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	strs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strs = append(strs, id.String())
+	}
+
+	return strs
 }
 
 func getBulkInsertSQL(SQLString string, rowValueSQL string, numRows int) string {
@@ -0,0 +1,69 @@
+package postgresql
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"game-project/internal/domain"
+	"game-project/internal/domain/domaintest"
+)
+
+// TRUNCATE_USERS empties the user table and, through the foreign keys, every
+// table referencing it.
+const TRUNCATE_USERS = `TRUNCATE public.user CASCADE;`
+
+// TestUserRepositoryImpl_Contract runs against the database named by the
+// pgTestURL environment variable, after migrating it. Every table is emptied
+// before each case, so the database name must end with _test.
+func TestUserRepositoryImpl_Contract(t *testing.T) {
+	pool := connectTestDatabase(t)
+
+	domaintest.UserRepositoryContract(t, func(t *testing.T) domain.UserRepository {
+		if _, err := pool.Exec(context.Background(), TRUNCATE_USERS); err != nil {
+			t.Fatalf("emptying the tables: %v", err)
+		}
+		return NewUserRepository(pool, Timeouts{Read: 5 * time.Second, Write: 5 * time.Second})
+	})
+}
+
+// connectTestDatabase migrates the database named by the pgTestURL
+// environment variable and connects to it, the test is skipped when it is
+// not set. Only databases named *_test are accepted, so that the tests
+// cannot empty the development database by mistake.
+func connectTestDatabase(t *testing.T) *pgxpool.Pool {
+	dbURL := os.Getenv("pgTestURL")
+	if dbURL == "" {
+		t.Skip("pgTestURL is not set")
+	}
+	parsed, err := url.Parse(dbURL)
+	if err != nil {
+		t.Fatalf("parsing pgTestURL: %v", err)
+	}
+	if name := strings.TrimPrefix(parsed.Path, "/"); !strings.HasSuffix(name, "_test") {
+		t.Fatalf("pgTestURL names the database %q, the tests empty it so its name must end with _test", name)
+	}
+
+	m, err := migrate.New("file://../../../data/migrations", dbURL)
+	if err != nil {
+		t.Fatalf("preparing the migrations: %v", err)
+	}
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	pool, err := pgxpool.Connect(context.Background(), dbURL)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
@@ -0,0 +1,81 @@
+// Package tracing sets up OpenTelemetry and records a span for every HTTP
+// request, continuing the trace of the caller when it sent a W3C traceparent.
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"game-project/internal/adapters/http/middleware"
+)
+
+const serviceName = "game-project"
+
+var tracer = otel.Tracer("game-project/internal/adapters/tracing")
+
+// Setup installs the W3C trace-context propagator and, when an exporter is
+// given, the tracer provider sending every span to it. The returned function
+// flushes the pending spans and must be called before the application exits.
+func Setup(exporter sdktrace.SpanExporter) func(ctx context.Context) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if exporter == nil {
+		return func(ctx context.Context) error { return nil }
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown
+}
+
+// StdoutExporter writes the spans to the standard output, for development.
+func StdoutExporter() (sdktrace.SpanExporter, error) {
+	return stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+}
+
+// OTLPExporter sends the spans over HTTP, without TLS, to the collector at
+// the endpoint, a host:port such as a local OpenTelemetry collector.
+func OTLPExporter(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
+	return otlptracehttp.New(ctx, otlptracehttp.WithEndpoint(endpoint), otlptracehttp.WithInsecure())
+}
+
+// Middleware records a server span named after the template of the matched
+// route. It must be used as a mux middleware, which only runs once a route
+// matched.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		route := request.URL.Path
+		if current := mux.CurrentRoute(request); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+		ctx, span := tracer.Start(ctx, request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(serviceName, route, request)...),
+		)
+		defer span.End()
+
+		recorder := middleware.NewStatusRecorder(writer)
+		next.ServeHTTP(recorder, request.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(recorder.Status)...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(recorder.Status))
+	})
+}
@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	Setup(nil)
+
+	var handlerSpan trace.SpanContext
+	r := mux.NewRouter()
+	r.Use(Middleware)
+	r.HandleFunc("/user/{userId}", func(writer http.ResponseWriter, request *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(request.Context())
+		writer.WriteHeader(http.StatusInternalServerError)
+	}).Methods("GET")
+
+	request := httptest.NewRequest("GET", "/user/42", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected a single span, actual: %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /user/{userId}" {
+		t.Errorf("expected the span to be named after the route template, actual: %s", span.Name())
+	}
+	if span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected the trace of the traceparent header to continue, actual: %s", span.SpanContext().TraceID())
+	}
+	if span.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("expected the caller's span as the parent, actual: %s", span.Parent().SpanID())
+	}
+	if handlerSpan.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("expected the handler to run within the span")
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("expected a 500 to mark the span as failed, actual: %v", span.Status())
+	}
+}
@@ -0,0 +1,69 @@
+package application
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/application/command"
+	"game-project/internal/application/query"
+	"game-project/internal/domain"
+	"game-project/internal/logging"
+)
+
+var (
+	ErrUserBlocked = domain.ForbiddenError("user_blocked", "one of the users blocked the other")
+	ErrSelfBlock   = domain.ValidationError("self_block", "users cannot block themselves", domain.FieldError{Field: "userId", Message: "must not be the blocking user"})
+	ErrNotBlocked  = domain.NotFoundError("not_blocked", "user is not blocked")
+)
+
+// BlockUser blocks another user, which also unfriends them and cancels any
+// pending friend request between both.
+func (s *UserServiceImpl) BlockUser(ctx context.Context, userId uuid.UUID, command command.BlockUser) error {
+	if userId == command.UserId {
+		return ErrSelfBlock
+	}
+	if _, err := s.findUser(ctx, userId); err != nil {
+		return err
+	}
+	if _, err := s.findUser(ctx, command.UserId); err != nil {
+		return err
+	}
+
+	_, err := s.repository.BlockUser(ctx, userId, command.UserId)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("could not block user %s for user %s, error: %s", command.UserId, userId, err)
+	}
+
+	return err
+}
+
+func (s *UserServiceImpl) UnblockUser(ctx context.Context, userId uuid.UUID, blockedId uuid.UUID) error {
+	n, err := s.repository.UnblockUser(ctx, userId, blockedId)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("could not unblock user %s for user %s, error: %s", blockedId, userId, err)
+		return err
+	}
+	if n == 0 {
+		return ErrNotBlocked
+	}
+
+	return nil
+}
+
+func (s *UserServiceImpl) ListBlocks(ctx context.Context, userId uuid.UUID) (*query.Blocks, error) {
+	if _, err := s.findUser(ctx, userId); err != nil {
+		return nil, err
+	}
+	blocked, err := s.repository.ListBlocks(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := query.Blocks{Blocked: []*query.User{}}
+	for _, usr := range blocked {
+		blocks.Blocked = append(blocks.Blocked, &query.User{Id: usr.Id, Name: usr.Name})
+	}
+
+	return &blocks, nil
+}
@@ -0,0 +1,85 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/application/command"
+	"game-project/internal/domain"
+)
+
+func TestUserServiceImpl_BlockUser(t *testing.T) {
+	userId, _ := uuid.NewV4()
+	blockedId, _ := uuid.NewV4()
+	cases := []struct {
+		name           string
+		fakeRepository *fakeUserRepository
+		blockedId      uuid.UUID
+		expectedErr    error
+	}{
+		{
+			name:           "block another user",
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{Id: blockedId}, touchedRowsMock: 1},
+			blockedId:      blockedId,
+			expectedErr:    nil,
+		},
+		{
+			name:           "block oneself",
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{Id: userId}},
+			blockedId:      userId,
+			expectedErr:    ErrSelfBlock,
+		},
+	}
+
+	for _, tc := range cases {
+		service := UserServiceImpl{repository: tc.fakeRepository}
+		err := service.BlockUser(context.Background(), userId, command.BlockUser{UserId: tc.blockedId})
+		if err != tc.expectedErr {
+			t.Errorf("%s: expected err %v, actual: %v", tc.name, tc.expectedErr, err)
+		}
+	}
+}
+
+func TestUserServiceImpl_BlockedUsersCannotBefriend(t *testing.T) {
+	userId, _ := uuid.NewV4()
+	blockedId, _ := uuid.NewV4()
+	fakeRepository := &fakeUserRepository{
+		findUserMock:     &domain.User{Id: blockedId},
+		blockedAmongMock: []uuid.UUID{blockedId},
+	}
+	service := UserServiceImpl{repository: fakeRepository}
+
+	if _, err := service.PatchUserFriends(context.Background(), userId, command.UpdateUserFriends{Add: []uuid.UUID{blockedId}}); err != ErrUserBlocked {
+		t.Errorf("adding a blocked friend: expected err %v, actual: %v", ErrUserBlocked, err)
+	}
+	if _, err := service.UpdateUserFriends(context.Background(), userId, command.UpdateUserFriends{Friends: []uuid.UUID{blockedId}}); err != ErrUserBlocked {
+		t.Errorf("replacing friends with a blocked one: expected err %v, actual: %v", ErrUserBlocked, err)
+	}
+	if _, err := service.SendFriendRequest(context.Background(), userId, command.SendFriendRequest{FriendId: blockedId}); err != ErrUserBlocked {
+		t.Errorf("sending a friend request to a blocked user: expected err %v, actual: %v", ErrUserBlocked, err)
+	}
+}
+
+func TestUserServiceImpl_BlockCheckFailsClosed(t *testing.T) {
+	userId, _ := uuid.NewV4()
+	friendId, _ := uuid.NewV4()
+	dbErr := errors.New("connection refused")
+	service := UserServiceImpl{repository: &fakeUserRepository{findUserMock: &domain.User{Id: friendId}, errMock: dbErr}}
+
+	if _, err := service.PatchUserFriends(context.Background(), userId, command.UpdateUserFriends{Add: []uuid.UUID{friendId}}); err != dbErr {
+		t.Errorf("adding a friend: expected err %v, actual: %v", dbErr, err)
+	}
+	if _, err := service.SendFriendRequest(context.Background(), userId, command.SendFriendRequest{FriendId: friendId}); err != dbErr {
+		t.Errorf("sending a friend request: expected err %v, actual: %v", dbErr, err)
+	}
+}
+
+func TestUserServiceImpl_UnblockUser(t *testing.T) {
+	service := UserServiceImpl{repository: &fakeUserRepository{touchedRowsMock: 0}}
+	if err := service.UnblockUser(context.Background(), uuid.UUID{}, uuid.UUID{}); err != ErrNotBlocked {
+		t.Errorf("expected err %v, actual: %v", ErrNotBlocked, err)
+	}
+}
@@ -0,0 +1,7 @@
+package command
+
+import "github.com/gofrs/uuid"
+
+type BlockUser struct {
+	UserId uuid.UUID `json:"userId"`
+}
@@ -0,0 +1,8 @@
+package command
+
+import "github.com/gofrs/uuid"
+
+type IssueToken struct {
+	UserId uuid.UUID `json:"userId"`
+	Secret string    `json:"secret"`
+}
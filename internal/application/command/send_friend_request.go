@@ -0,0 +1,7 @@
+package command
+
+import "github.com/gofrs/uuid"
+
+type SendFriendRequest struct {
+	FriendId uuid.UUID `json:"friendId"`
+}
@@ -0,0 +1,5 @@
+package command
+
+type SetLogLevel struct {
+	Level string `json:"level"`
+}
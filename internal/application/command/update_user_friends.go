@@ -3,5 +3,12 @@ package command
 import "github.com/gofrs/uuid"
 
 type UpdateUserFriends struct {
-	Friends []uuid.UUID `json:"friends"`
+	// Friends is the complete friend list, replacing the current one on PUT.
+	// It is required on PUT, where an empty list removes every friend, and
+	// rejected on PATCH.
+	Friends []uuid.UUID `json:"friends,omitempty"`
+	// Add and Remove change the friend list incrementally on PATCH, they are
+	// rejected on PUT.
+	Add    []uuid.UUID `json:"add,omitempty"`
+	Remove []uuid.UUID `json:"remove,omitempty"`
 }
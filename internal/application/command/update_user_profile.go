@@ -0,0 +1,10 @@
+package command
+
+// UpdateUserProfile only changes the fields that are present. An empty
+// string clears the country, avatar URL or bio.
+type UpdateUserProfile struct {
+	Name      *string `json:"name,omitempty"`
+	Country   *string `json:"country,omitempty"`
+	AvatarUrl *string `json:"avatarUrl,omitempty"`
+	Bio       *string `json:"bio,omitempty"`
+}
@@ -1,6 +1,10 @@
 package command
 
+import "encoding/json"
+
 type UpdateUserState struct {
-	GamesPlayed uint8 `json:"gamesPlayed"`
-	Score       uint  `json:"score"`
+	GamesPlayed uint8           `json:"gamesPlayed"`
+	Score       uint            `json:"score"`
+	DurationMs  uint32          `json:"durationMs,omitempty"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
 }
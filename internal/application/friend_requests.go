@@ -0,0 +1,146 @@
+package application
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/application/command"
+	"game-project/internal/application/query"
+	"game-project/internal/domain"
+	"game-project/internal/logging"
+)
+
+var (
+	ErrFriendRequestNotFound   = domain.NotFoundError("friend_request_not_found", "friend request not found")
+	ErrFriendRequestNotPending = domain.ConflictError("friend_request_not_pending", "friend request is no longer pending")
+	ErrFriendRequestExists     = domain.ConflictError("friend_request_exists", "a pending friend request between these users already exists")
+	ErrSelfFriendRequest       = domain.ValidationError("self_friend_request", "users cannot befriend themselves", domain.FieldError{Field: "friendId", Message: "must not be the sending user"})
+	ErrAlreadyFriends          = domain.ConflictError("already_friends", "users are already friends")
+)
+
+func (s *UserServiceImpl) SendFriendRequest(ctx context.Context, userId uuid.UUID, command command.SendFriendRequest) (*query.FriendRequest, error) {
+	if userId == command.FriendId {
+		return nil, ErrSelfFriendRequest
+	}
+	if _, err := s.findUser(ctx, userId); err != nil {
+		return nil, err
+	}
+	if _, err := s.findUser(ctx, command.FriendId); err != nil {
+		return nil, err
+	}
+	blocked, err := s.repository.BlockedAmong(ctx, userId, []uuid.UUID{command.FriendId})
+	if err != nil {
+		return nil, err
+	}
+	if len(blocked) > 0 {
+		return nil, ErrUserBlocked
+	}
+	friends, err := s.repository.ListFriends(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, friend := range friends {
+		if friend.Id == command.FriendId {
+			return nil, ErrAlreadyFriends
+		}
+	}
+	for _, direction := range []domain.FriendRequestDirection{domain.FriendRequestIncoming, domain.FriendRequestOutgoing} {
+		pending, err := s.repository.ListFriendRequests(ctx, userId, direction)
+		if err != nil {
+			return nil, err
+		}
+		for _, request := range pending {
+			if request.FromUserId == command.FriendId || request.ToUserId == command.FriendId {
+				return nil, ErrFriendRequestExists
+			}
+		}
+	}
+
+	request, err := s.repository.CreateFriendRequest(ctx, userId, command.FriendId)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("could not create friend request from %s to %s, error: %s", userId, command.FriendId, err)
+		return nil, err
+	}
+
+	return toFriendRequestQuery(request), nil
+}
+
+func (s *UserServiceImpl) ListFriendRequests(ctx context.Context, userId uuid.UUID, direction domain.FriendRequestDirection) (*query.FriendRequests, error) {
+	if _, err := s.findUser(ctx, userId); err != nil {
+		return nil, err
+	}
+	pending, err := s.repository.ListFriendRequests(ctx, userId, direction)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := query.FriendRequests{Requests: []*query.FriendRequest{}}
+	for _, request := range pending {
+		requests.Requests = append(requests.Requests, toFriendRequestQuery(request))
+	}
+
+	return &requests, nil
+}
+
+// AcceptFriendRequest makes the sender and the recipient friends of each
+// other. Only the recipient can accept a request.
+func (s *UserServiceImpl) AcceptFriendRequest(ctx context.Context, userId uuid.UUID, requestId uuid.UUID) error {
+	return s.resolveFriendRequest(ctx, requestId, domain.FriendRequestAccepted, func(request *domain.FriendRequest) bool {
+		return request.ToUserId == userId
+	})
+}
+
+func (s *UserServiceImpl) DeclineFriendRequest(ctx context.Context, userId uuid.UUID, requestId uuid.UUID) error {
+	return s.resolveFriendRequest(ctx, requestId, domain.FriendRequestDeclined, func(request *domain.FriendRequest) bool {
+		return request.ToUserId == userId
+	})
+}
+
+// CancelFriendRequest withdraws a request. Only the sender can cancel it.
+func (s *UserServiceImpl) CancelFriendRequest(ctx context.Context, userId uuid.UUID, requestId uuid.UUID) error {
+	return s.resolveFriendRequest(ctx, requestId, domain.FriendRequestCancelled, func(request *domain.FriendRequest) bool {
+		return request.FromUserId == userId
+	})
+}
+
+// resolveFriendRequest moves a pending request to its final status. Requests
+// that do not belong to the user are reported as not found so that request
+// ids of other users cannot be probed.
+func (s *UserServiceImpl) resolveFriendRequest(ctx context.Context, requestId uuid.UUID, status domain.FriendRequestStatus, belongsToUser func(*domain.FriendRequest) bool) error {
+	request, err := s.repository.FindFriendRequest(ctx, requestId)
+	if err != nil {
+		return err
+	}
+	if request == nil || !belongsToUser(request) {
+		return ErrFriendRequestNotFound
+	}
+	if request.Status != domain.FriendRequestPending {
+		return ErrFriendRequestNotPending
+	}
+
+	n, err := s.repository.ResolveFriendRequest(ctx, requestId, status)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("could not mark friend request %s as %s, error: %s", requestId, status, err)
+		return err
+	}
+	if n == 0 {
+		return ErrFriendRequestNotPending
+	}
+	if status == domain.FriendRequestAccepted {
+		s.metrics().FriendsAdded(2)
+	}
+
+	return nil
+}
+
+func toFriendRequestQuery(request *domain.FriendRequest) *query.FriendRequest {
+	return &query.FriendRequest{
+		Id:         request.Id,
+		FromUserId: request.FromUserId,
+		ToUserId:   request.ToUserId,
+		Status:     string(request.Status),
+		CreatedAt:  request.CreatedAt,
+		UpdatedAt:  request.UpdatedAt,
+	}
+}
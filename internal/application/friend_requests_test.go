@@ -0,0 +1,145 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/application/command"
+	"game-project/internal/domain"
+)
+
+func TestUserServiceImpl_SendFriendRequest(t *testing.T) {
+	userId, _ := uuid.NewV4()
+	friendId, _ := uuid.NewV4()
+	cases := []struct {
+		name           string
+		fakeRepository *fakeUserRepository
+		friendId       uuid.UUID
+		expectedErr    error
+	}{
+		{
+			name: "new request",
+			fakeRepository: &fakeUserRepository{
+				findUserMock:      &domain.User{Id: friendId},
+				friendRequestMock: &domain.FriendRequest{FromUserId: userId, ToUserId: friendId, Status: domain.FriendRequestPending},
+			},
+			friendId:    friendId,
+			expectedErr: nil,
+		},
+		{
+			name:           "request to oneself",
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{Id: userId}},
+			friendId:       userId,
+			expectedErr:    ErrSelfFriendRequest,
+		},
+		{
+			name: "request to a friend",
+			fakeRepository: &fakeUserRepository{
+				findUserMock:    &domain.User{Id: friendId},
+				listFriendsMock: []*domain.User{{Id: friendId}},
+			},
+			friendId:    friendId,
+			expectedErr: ErrAlreadyFriends,
+		},
+		{
+			name: "request already pending the other way around",
+			fakeRepository: &fakeUserRepository{
+				findUserMock:       &domain.User{Id: friendId},
+				friendRequestsMock: []*domain.FriendRequest{{FromUserId: friendId, ToUserId: userId}},
+			},
+			friendId:    friendId,
+			expectedErr: ErrFriendRequestExists,
+		},
+	}
+
+	for _, tc := range cases {
+		service := UserServiceImpl{repository: tc.fakeRepository}
+		response, err := service.SendFriendRequest(context.Background(), userId, command.SendFriendRequest{FriendId: tc.friendId})
+		if err != tc.expectedErr {
+			t.Errorf("%s: expected err %v, actual: %v", tc.name, tc.expectedErr, err)
+		}
+		if err == nil && (response == nil || response.Status != string(domain.FriendRequestPending)) {
+			t.Errorf("%s: expected a pending friend request, actual: %+v", tc.name, response)
+		}
+	}
+}
+
+func TestUserServiceImpl_ResolveFriendRequest(t *testing.T) {
+	senderId, _ := uuid.NewV4()
+	recipientId, _ := uuid.NewV4()
+	pending := &domain.FriendRequest{FromUserId: senderId, ToUserId: recipientId, Status: domain.FriendRequestPending}
+	declined := &domain.FriendRequest{FromUserId: senderId, ToUserId: recipientId, Status: domain.FriendRequestDeclined}
+
+	cases := []struct {
+		name           string
+		fakeRepository *fakeUserRepository
+		resolve        func(s *UserServiceImpl) error
+		expectedErr    error
+	}{
+		{
+			name:           "recipient accepts",
+			fakeRepository: &fakeUserRepository{friendRequestMock: pending, touchedRowsMock: 1},
+			resolve: func(s *UserServiceImpl) error {
+				return s.AcceptFriendRequest(context.Background(), recipientId, uuid.UUID{})
+			},
+			expectedErr: nil,
+		},
+		{
+			name:           "sender cannot accept",
+			fakeRepository: &fakeUserRepository{friendRequestMock: pending, touchedRowsMock: 1},
+			resolve: func(s *UserServiceImpl) error {
+				return s.AcceptFriendRequest(context.Background(), senderId, uuid.UUID{})
+			},
+			expectedErr: ErrFriendRequestNotFound,
+		},
+		{
+			name:           "sender cancels",
+			fakeRepository: &fakeUserRepository{friendRequestMock: pending, touchedRowsMock: 1},
+			resolve: func(s *UserServiceImpl) error {
+				return s.CancelFriendRequest(context.Background(), senderId, uuid.UUID{})
+			},
+			expectedErr: nil,
+		},
+		{
+			name:           "recipient cannot cancel",
+			fakeRepository: &fakeUserRepository{friendRequestMock: pending, touchedRowsMock: 1},
+			resolve: func(s *UserServiceImpl) error {
+				return s.CancelFriendRequest(context.Background(), recipientId, uuid.UUID{})
+			},
+			expectedErr: ErrFriendRequestNotFound,
+		},
+		{
+			name:           "request already declined",
+			fakeRepository: &fakeUserRepository{friendRequestMock: declined},
+			resolve: func(s *UserServiceImpl) error {
+				return s.AcceptFriendRequest(context.Background(), recipientId, uuid.UUID{})
+			},
+			expectedErr: ErrFriendRequestNotPending,
+		},
+		{
+			name:           "request resolved concurrently",
+			fakeRepository: &fakeUserRepository{friendRequestMock: pending, touchedRowsMock: 0},
+			resolve: func(s *UserServiceImpl) error {
+				return s.DeclineFriendRequest(context.Background(), recipientId, uuid.UUID{})
+			},
+			expectedErr: ErrFriendRequestNotPending,
+		},
+		{
+			name:           "unknown request",
+			fakeRepository: &fakeUserRepository{},
+			resolve: func(s *UserServiceImpl) error {
+				return s.DeclineFriendRequest(context.Background(), recipientId, uuid.UUID{})
+			},
+			expectedErr: ErrFriendRequestNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		service := &UserServiceImpl{repository: tc.fakeRepository}
+		if err := tc.resolve(service); err != tc.expectedErr {
+			t.Errorf("%s: expected err %v, actual: %v", tc.name, tc.expectedErr, err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package application
+
+// Metrics counts the game events handled by the service. Implementations
+// must be safe for concurrent use.
+type Metrics interface {
+	UserCreated()
+	ScoreSubmitted()
+	// HighScoreImproved is counted along with ScoreSubmitted when the score
+	// beats the user's best one.
+	HighScoreImproved()
+	// FriendsAdded counts the entries added to friend lists. An accepted
+	// friend request counts as two, one for each list.
+	FriendsAdded(count int64)
+}
+
+type noMetrics struct{}
+
+func (noMetrics) UserCreated()             {}
+func (noMetrics) ScoreSubmitted()          {}
+func (noMetrics) HighScoreImproved()       {}
+func (noMetrics) FriendsAdded(count int64) {}
+
+func (s *UserServiceImpl) metrics() Metrics {
+	if s.config.Metrics == nil {
+		return noMetrics{}
+	}
+	return s.config.Metrics
+}
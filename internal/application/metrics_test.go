@@ -0,0 +1,92 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/application/command"
+	"game-project/internal/domain"
+)
+
+type fakeMetrics struct {
+	counts map[string]int64
+}
+
+func (f fakeMetrics) UserCreated()             { f.counts["usersCreated"]++ }
+func (f fakeMetrics) ScoreSubmitted()          { f.counts["scoreSubmissions"]++ }
+func (f fakeMetrics) HighScoreImproved()       { f.counts["highScores"]++ }
+func (f fakeMetrics) FriendsAdded(count int64) { f.counts["friendsAdded"] += count }
+
+func TestUserServiceImpl_Metrics(t *testing.T) {
+	friendId, _ := uuid.NewV4()
+	cases := []struct {
+		name           string
+		fakeRepository *fakeUserRepository
+		call           func(s *UserServiceImpl) error
+		expectedCounts map[string]int64
+	}{
+		{
+			name:           "user created",
+			fakeRepository: &fakeUserRepository{createMock: &domain.User{Name: "Don"}},
+			call: func(s *UserServiceImpl) error {
+				_, err := s.CreateUser(context.Background(), command.CreateUser{Name: "Don"})
+				return err
+			},
+			expectedCounts: map[string]int64{"usersCreated": 1},
+		},
+		{
+			name:           "new high score",
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{Score: sql.NullInt64{Int64: 50, Valid: true}}},
+			call: func(s *UserServiceImpl) error {
+				return s.UpdateUserState(context.Background(), uuid.UUID{}, command.UpdateUserState{Score: 80})
+			},
+			expectedCounts: map[string]int64{"scoreSubmissions": 1, "highScores": 1},
+		},
+		{
+			name:           "lower score",
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{Score: sql.NullInt64{Int64: 50, Valid: true}}},
+			call: func(s *UserServiceImpl) error {
+				return s.UpdateUserState(context.Background(), uuid.UUID{}, command.UpdateUserState{Score: 20})
+			},
+			expectedCounts: map[string]int64{"scoreSubmissions": 1},
+		},
+		{
+			name:           "first score",
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{}},
+			call: func(s *UserServiceImpl) error {
+				return s.UpdateUserState(context.Background(), uuid.UUID{}, command.UpdateUserState{Score: 0})
+			},
+			expectedCounts: map[string]int64{"scoreSubmissions": 1, "highScores": 1},
+		},
+		{
+			name:           "friends added",
+			fakeRepository: &fakeUserRepository{touchedRowsMock: 1},
+			call: func(s *UserServiceImpl) error {
+				_, err := s.PatchUserFriends(context.Background(), uuid.Must(uuid.NewV4()), command.UpdateUserFriends{Add: []uuid.UUID{friendId}})
+				return err
+			},
+			expectedCounts: map[string]int64{"friendsAdded": 1},
+		},
+	}
+
+	for _, tc := range cases {
+		metrics := fakeMetrics{counts: make(map[string]int64)}
+		service := &UserServiceImpl{repository: tc.fakeRepository, config: Config{Metrics: metrics}}
+		if err := tc.call(service); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if len(metrics.counts) != len(tc.expectedCounts) {
+			t.Errorf("%s: expected %v, actual: %v", tc.name, tc.expectedCounts, metrics.counts)
+			continue
+		}
+		for name, count := range tc.expectedCounts {
+			if metrics.counts[name] != count {
+				t.Errorf("%s: expected %v, actual: %v", tc.name, tc.expectedCounts, metrics.counts)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/application/command"
+	"game-project/internal/application/query"
+	"game-project/internal/domain"
+	"game-project/internal/logging"
+)
+
+var (
+	ErrRenameCooldown = domain.ConflictError("rename_cooldown", "the name was changed too recently")
+	ErrEmptyName      = domain.ValidationError("invalid_name", "the name must not be empty", domain.FieldError{Field: "name", Message: "must not be empty"})
+)
+
+func (s *UserServiceImpl) GetUserProfile(ctx context.Context, userId uuid.UUID) (*query.UserProfile, error) {
+	usr, err := s.findUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toUserProfile(ctx, usr)
+}
+
+// UpdateUserProfile changes the fields present in the command once they are
+// all valid. Renaming is only allowed once per RenameCooldown.
+func (s *UserServiceImpl) UpdateUserProfile(ctx context.Context, userId uuid.UUID, command command.UpdateUserProfile) (*query.UserProfile, error) {
+	usr, err := s.findUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if command.Name != nil && *command.Name == "" {
+		return nil, ErrEmptyName
+	}
+	if err := s.validateUpdateUserProfile(command); err != nil {
+		return nil, err
+	}
+	if command.Name != nil && *command.Name != usr.Name {
+		if usr.NameChangedAt.Valid && time.Since(usr.NameChangedAt.Time) < s.config.RenameCooldown {
+			return nil, ErrRenameCooldown
+		}
+		usr.Name = *command.Name
+	}
+	setProfileField(&usr.Country, command.Country)
+	setProfileField(&usr.AvatarUrl, command.AvatarUrl)
+	setProfileField(&usr.Bio, command.Bio)
+
+	err = s.repository.UpdateProfile(ctx, usr)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("could not update profile of user %s, error: %s", userId, err)
+		return nil, err
+	}
+
+	return s.toUserProfile(ctx, usr)
+}
+
+func (s *UserServiceImpl) DeleteUser(ctx context.Context, userId uuid.UUID) error {
+	n, err := s.repository.Delete(ctx, userId)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("could not delete user %s, error: %s", userId, err)
+		return err
+	}
+	if n == 0 {
+		return errUserNotFound(userId)
+	}
+
+	return nil
+}
+
+func (s *UserServiceImpl) toUserProfile(ctx context.Context, usr *domain.User) (*query.UserProfile, error) {
+	history, err := s.repository.ListNameHistory(ctx, usr.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := query.UserProfile{
+		Id:          usr.Id,
+		Name:        usr.Name,
+		Country:     usr.Country.String,
+		AvatarUrl:   usr.AvatarUrl.String,
+		Bio:         usr.Bio.String,
+		GamesPlayed: usr.GamesPlayed.Int32,
+		Score:       usr.Score.Int64,
+	}
+	for _, change := range history {
+		profile.NameHistory = append(profile.NameHistory, &query.NameChange{Name: change.Name, ChangedAt: change.ChangedAt})
+	}
+
+	return &profile, nil
+}
+
+// setProfileField applies an optional update, an empty value clears the field.
+func setProfileField(field *sql.NullString, value *string) {
+	if value == nil {
+		return
+	}
+	*field = sql.NullString{String: *value, Valid: *value != ""}
+}
@@ -0,0 +1,108 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/application/command"
+	"game-project/internal/domain"
+)
+
+func TestUserServiceImpl_UpdateUserProfile(t *testing.T) {
+	newName := "Jessie"
+	emptyName := ""
+	bio := "Plays every night"
+	cases := []struct {
+		name           string
+		fakeRepository *fakeUserRepository
+		command        command.UpdateUserProfile
+		expectedName   string
+		expectedErr    error
+	}{
+		{
+			name:           "first rename",
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{Name: "Jessica"}},
+			command:        command.UpdateUserProfile{Name: &newName},
+			expectedName:   newName,
+		},
+		{
+			name: "rename after the cooldown",
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{
+				Name:          "Jessica",
+				NameChangedAt: sql.NullTime{Time: time.Now().Add(-2 * time.Hour), Valid: true},
+			}},
+			command:      command.UpdateUserProfile{Name: &newName},
+			expectedName: newName,
+		},
+		{
+			name: "rename during the cooldown",
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{
+				Name:          "Jessica",
+				NameChangedAt: sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+			}},
+			command:     command.UpdateUserProfile{Name: &newName},
+			expectedErr: ErrRenameCooldown,
+		},
+		{
+			name: "profile change during the cooldown",
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{
+				Name:          "Jessica",
+				NameChangedAt: sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+			}},
+			command:      command.UpdateUserProfile{Bio: &bio},
+			expectedName: "Jessica",
+		},
+		{
+			name:           "empty name",
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{Name: "Jessica"}},
+			command:        command.UpdateUserProfile{Name: &emptyName},
+			expectedErr:    ErrEmptyName,
+		},
+	}
+
+	for _, tc := range cases {
+		service := UserServiceImpl{repository: tc.fakeRepository, config: Config{RenameCooldown: time.Hour}}
+		profile, err := service.UpdateUserProfile(context.Background(), uuid.UUID{}, tc.command)
+		if err != tc.expectedErr {
+			t.Errorf("%s: expected err %v, actual: %v", tc.name, tc.expectedErr, err)
+			continue
+		}
+		if err == nil && profile.Name != tc.expectedName {
+			t.Errorf("%s: expected name %s, actual: %s", tc.name, tc.expectedName, profile.Name)
+		}
+	}
+}
+
+func TestUserServiceImpl_UpdateUserProfileClearsFields(t *testing.T) {
+	empty := ""
+	fakeRepository := &fakeUserRepository{findUserMock: &domain.User{
+		Name:    "Jessica",
+		Country: sql.NullString{String: "BR", Valid: true},
+		Bio:     sql.NullString{String: "Hi", Valid: true},
+	}}
+	service := UserServiceImpl{repository: fakeRepository}
+
+	profile, err := service.UpdateUserProfile(context.Background(), uuid.UUID{}, command.UpdateUserProfile{Country: &empty})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if profile.Country != "" || profile.Bio != "Hi" {
+		t.Errorf("expected only the country to be cleared, actual: %+v", profile)
+	}
+}
+
+func TestUserServiceImpl_DeleteUser(t *testing.T) {
+	service := UserServiceImpl{repository: &fakeUserRepository{touchedRowsMock: 0}}
+	if err := service.DeleteUser(context.Background(), uuid.UUID{}); err == nil {
+		t.Error("expected an error when deleting an unknown user")
+	}
+
+	service = UserServiceImpl{repository: &fakeUserRepository{touchedRowsMock: 1}}
+	if err := service.DeleteUser(context.Background(), uuid.UUID{}); err != nil {
+		t.Errorf("expected no err, actual: %v", err)
+	}
+}
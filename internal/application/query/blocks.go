@@ -0,0 +1,5 @@
+package query
+
+type Blocks struct {
+	Blocked []*User `json:"blocked"`
+}
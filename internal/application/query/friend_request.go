@@ -0,0 +1,20 @@
+package query
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type FriendRequest struct {
+	Id         uuid.UUID `json:"id"`
+	FromUserId uuid.UUID `json:"fromUserId"`
+	ToUserId   uuid.UUID `json:"toUserId"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type FriendRequests struct {
+	Requests []*FriendRequest `json:"requests"`
+}
@@ -0,0 +1,19 @@
+package query
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type GameSession struct {
+	Id          int64           `json:"id"`
+	Score       uint            `json:"score"`
+	DurationMs  int64           `json:"durationMs,omitempty"`
+	SubmittedAt time.Time       `json:"submittedAt"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
+}
+
+type GameSessions struct {
+	Sessions   []*GameSession `json:"sessions"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,6 @@
+package query
+
+type Health struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
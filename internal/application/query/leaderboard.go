@@ -0,0 +1,25 @@
+package query
+
+import "github.com/gofrs/uuid"
+
+type LeaderboardEntry struct {
+	Rank  int64     `json:"rank"`
+	Id    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Score int64     `json:"score"`
+}
+
+type Leaderboard struct {
+	Period  string              `json:"period"`
+	Limit   uint                `json:"limit"`
+	Offset  uint                `json:"offset"`
+	Entries []*LeaderboardEntry `json:"entries"`
+}
+
+// UserRank holds the requested user's own entry and the window of players
+// ranked directly above and below them, the user included.
+type UserRank struct {
+	Period  string              `json:"period"`
+	User    *LeaderboardEntry   `json:"user"`
+	Entries []*LeaderboardEntry `json:"entries"`
+}
@@ -0,0 +1,5 @@
+package query
+
+type LogLevel struct {
+	Level string `json:"level"`
+}
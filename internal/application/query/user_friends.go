@@ -11,3 +11,21 @@ type Friend struct {
 type UserFriends struct {
 	Friends []*Friend `json:"friends,omitempty"`
 }
+
+// FriendRank is a Friend placed on the leaderboard formed by a user and their
+// friends. Delta is the friend's score minus the user's own score.
+type FriendRank struct {
+	Friend
+	Rank  int64 `json:"rank"`
+	Delta int64 `json:"delta"`
+}
+
+type FriendsLeaderboard struct {
+	Period  string        `json:"period"`
+	Friends []*FriendRank `json:"friends"`
+}
+
+type FriendsUpdated struct {
+	Added   int64 `json:"added"`
+	Removed int64 `json:"removed"`
+}
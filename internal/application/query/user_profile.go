@@ -0,0 +1,23 @@
+package query
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type NameChange struct {
+	Name      string    `json:"name"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+type UserProfile struct {
+	Id          uuid.UUID     `json:"id"`
+	Name        string        `json:"name"`
+	Country     string        `json:"country,omitempty"`
+	AvatarUrl   string        `json:"avatarUrl,omitempty"`
+	Bio         string        `json:"bio,omitempty"`
+	GamesPlayed int32         `json:"gamesPlayed"`
+	Score       int64         `json:"score"`
+	NameHistory []*NameChange `json:"nameHistory,omitempty"`
+}
@@ -7,4 +7,19 @@ import (
 type User struct {
 	Id uuid.UUID `json:"id"`
 	Name string `json:"name"`
-}
\ No newline at end of file
+	GamesPlayed int32 `json:"gamesPlayed,omitempty"`
+	Score int64 `json:"score,omitempty"`
+	// Secret is only returned once, when the user is created. It is
+	// exchanged for access tokens at /auth/token.
+	Secret string `json:"secret,omitempty"`
+}
+type Users struct {
+	Users      []*User `json:"users"`
+	NextCursor string  `json:"nextCursor,omitempty"`
+}
+
+// UserSecret carries a newly issued secret, it is only returned once.
+type UserSecret struct {
+	Id     uuid.UUID `json:"id"`
+	Secret string    `json:"secret"`
+}
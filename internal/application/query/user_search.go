@@ -0,0 +1,8 @@
+package query
+
+type UserSearch struct {
+	Query  string  `json:"query"`
+	Limit  uint    `json:"limit"`
+	Offset uint    `json:"offset"`
+	Users  []*User `json:"users"`
+}
@@ -0,0 +1,39 @@
+package application
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/application/query"
+	"game-project/internal/domain"
+)
+
+var ErrEmptySearch = domain.ValidationError("invalid_search", "the search term must not be empty", domain.FieldError{Field: "q", Message: "must not be empty"})
+
+// SearchUsers looks users up by name, the ones whose name starts with the
+// term first, then the ones with a similar name.
+func (s *UserServiceImpl) SearchUsers(ctx context.Context, viewerId uuid.UUID, term string, limit uint, offset uint) (*query.UserSearch, error) {
+	term = strings.TrimSpace(term)
+	if term == "" {
+		return nil, ErrEmptySearch
+	}
+
+	found, err := s.repository.Search(ctx, term, viewerId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	search := query.UserSearch{Query: term, Limit: limit, Offset: offset, Users: make([]*query.User, 0, limit)}
+	for _, usr := range found {
+		search.Users = append(search.Users, &query.User{
+			Id:          usr.Id,
+			Name:        usr.Name,
+			GamesPlayed: usr.GamesPlayed.Int32,
+			Score:       usr.Score.Int64,
+		})
+	}
+
+	return &search, nil
+}
@@ -0,0 +1,56 @@
+package application
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/application/query"
+	"game-project/internal/domain"
+)
+
+func TestUserServiceImpl_SearchUsers(t *testing.T) {
+	cases := []struct {
+		name           string
+		fakeRepository *fakeUserRepository
+		term           string
+		expectedResult *query.UserSearch
+		expectedErr    error
+	}{
+		{
+			name:           "matches",
+			fakeRepository: &fakeUserRepository{listMock: []*domain.User{{Name: "Jake"}, {Name: "Jade"}}},
+			term:           " ja ",
+			expectedResult: &query.UserSearch{
+				Query: "ja",
+				Limit: 10,
+				Users: []*query.User{{Name: "Jake"}, {Name: "Jade"}},
+			},
+		},
+		{
+			name:           "no matches",
+			fakeRepository: &fakeUserRepository{},
+			term:           "zz",
+			expectedResult: &query.UserSearch{Query: "zz", Limit: 10, Users: []*query.User{}},
+		},
+		{
+			name:           "blank term",
+			fakeRepository: &fakeUserRepository{},
+			term:           "  ",
+			expectedErr:    ErrEmptySearch,
+		},
+	}
+
+	for _, tc := range cases {
+		service := UserServiceImpl{repository: tc.fakeRepository}
+		result, err := service.SearchUsers(context.Background(), uuid.UUID{}, tc.term, 10, 0)
+		if err != tc.expectedErr {
+			t.Errorf("%s: expected err %v, actual: %v", tc.name, tc.expectedErr, err)
+		}
+		if !reflect.DeepEqual(result, tc.expectedResult) {
+			t.Errorf("%s: expected result %+v, actual: %+v", tc.name, tc.expectedResult, result)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package application
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"game-project/internal/domain"
+)
+
+var ErrInvalidCursor = domain.ValidationError("invalid_cursor", "invalid cursor", domain.FieldError{Field: "cursor", Message: "must be a cursor returned by a previous page"})
+
+// encodeSessionCursor turns the position of a session into the opaque token
+// handed out to clients as nextCursor.
+func encodeSessionCursor(session *domain.GameSession) string {
+	raw := fmt.Sprintf("%d:%d", session.SubmittedAt.UnixNano(), session.Id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeSessionCursor(cursor string) (*domain.SessionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &domain.SessionCursor{SubmittedAt: time.Unix(0, nanos), Id: id}, nil
+}
@@ -0,0 +1,240 @@
+package application
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofrs/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"game-project/internal/application/command"
+	"game-project/internal/application/query"
+	"game-project/internal/domain"
+)
+
+var tracer = otel.Tracer("game-project/internal/application")
+
+// NewTracedUserService wraps the service to record a span for every call,
+// a child of the span found in the context.
+func NewTracedUserService(next UserService) UserService {
+	return tracedUserService{next: next}
+}
+
+type tracedUserService struct {
+	next UserService
+}
+
+// recordError marks the span as failed, unless the error is an expected
+// outcome such as invalid input or a missing user.
+func recordError(span trace.Span, err error) error {
+	if err == nil {
+		return nil
+	}
+	span.RecordError(err)
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+func (s tracedUserService) ListUser(ctx context.Context, sort domain.UserSort, filter domain.UserFilter, cursor string, limit uint) (*query.Users, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ListUser")
+	defer span.End()
+
+	result, err := s.next.ListUser(ctx, sort, filter, cursor, limit)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) CreateUser(ctx context.Context, user command.CreateUser) (*query.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.CreateUser")
+	defer span.End()
+
+	result, err := s.next.CreateUser(ctx, user)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) Authenticate(ctx context.Context, command command.IssueToken) error {
+	ctx, span := tracer.Start(ctx, "UserService.Authenticate")
+	defer span.End()
+
+	return recordError(span, s.next.Authenticate(ctx, command))
+}
+
+func (s tracedUserService) ResetUserSecret(ctx context.Context, userId uuid.UUID) (*query.UserSecret, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ResetUserSecret")
+	defer span.End()
+
+	result, err := s.next.ResetUserSecret(ctx, userId)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) UpdateUserState(ctx context.Context, userId uuid.UUID, command command.UpdateUserState) error {
+	ctx, span := tracer.Start(ctx, "UserService.UpdateUserState")
+	defer span.End()
+
+	return recordError(span, s.next.UpdateUserState(ctx, userId, command))
+}
+
+func (s tracedUserService) LoadUserState(ctx context.Context, userId uuid.UUID) (*query.UserGameStateQuery, error) {
+	ctx, span := tracer.Start(ctx, "UserService.LoadUserState")
+	defer span.End()
+
+	result, err := s.next.LoadUserState(ctx, userId)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) SearchUsers(ctx context.Context, viewerId uuid.UUID, term string, limit uint, offset uint) (*query.UserSearch, error) {
+	ctx, span := tracer.Start(ctx, "UserService.SearchUsers")
+	defer span.End()
+
+	result, err := s.next.SearchUsers(ctx, viewerId, term, limit, offset)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) GetUserProfile(ctx context.Context, userId uuid.UUID) (*query.UserProfile, error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetUserProfile")
+	defer span.End()
+
+	result, err := s.next.GetUserProfile(ctx, userId)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) UpdateUserProfile(ctx context.Context, userId uuid.UUID, command command.UpdateUserProfile) (*query.UserProfile, error) {
+	ctx, span := tracer.Start(ctx, "UserService.UpdateUserProfile")
+	defer span.End()
+
+	result, err := s.next.UpdateUserProfile(ctx, userId, command)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) DeleteUser(ctx context.Context, userId uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "UserService.DeleteUser")
+	defer span.End()
+
+	return recordError(span, s.next.DeleteUser(ctx, userId))
+}
+
+func (s tracedUserService) UpdateUserFriends(ctx context.Context, userId uuid.UUID, command command.UpdateUserFriends) (*query.FriendsUpdated, error) {
+	ctx, span := tracer.Start(ctx, "UserService.UpdateUserFriends")
+	defer span.End()
+
+	result, err := s.next.UpdateUserFriends(ctx, userId, command)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) PatchUserFriends(ctx context.Context, userId uuid.UUID, command command.UpdateUserFriends) (*query.FriendsUpdated, error) {
+	ctx, span := tracer.Start(ctx, "UserService.PatchUserFriends")
+	defer span.End()
+
+	result, err := s.next.PatchUserFriends(ctx, userId, command)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) RemoveUserFriend(ctx context.Context, userId uuid.UUID, friendId uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "UserService.RemoveUserFriend")
+	defer span.End()
+
+	return recordError(span, s.next.RemoveUserFriend(ctx, userId, friendId))
+}
+
+func (s tracedUserService) ListUserFriends(ctx context.Context, userId uuid.UUID) (*query.UserFriends, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ListUserFriends")
+	defer span.End()
+
+	result, err := s.next.ListUserFriends(ctx, userId)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) SendFriendRequest(ctx context.Context, userId uuid.UUID, command command.SendFriendRequest) (*query.FriendRequest, error) {
+	ctx, span := tracer.Start(ctx, "UserService.SendFriendRequest")
+	defer span.End()
+
+	result, err := s.next.SendFriendRequest(ctx, userId, command)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) ListFriendRequests(ctx context.Context, userId uuid.UUID, direction domain.FriendRequestDirection) (*query.FriendRequests, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ListFriendRequests")
+	defer span.End()
+
+	result, err := s.next.ListFriendRequests(ctx, userId, direction)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) AcceptFriendRequest(ctx context.Context, userId uuid.UUID, requestId uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "UserService.AcceptFriendRequest")
+	defer span.End()
+
+	return recordError(span, s.next.AcceptFriendRequest(ctx, userId, requestId))
+}
+
+func (s tracedUserService) DeclineFriendRequest(ctx context.Context, userId uuid.UUID, requestId uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "UserService.DeclineFriendRequest")
+	defer span.End()
+
+	return recordError(span, s.next.DeclineFriendRequest(ctx, userId, requestId))
+}
+
+func (s tracedUserService) CancelFriendRequest(ctx context.Context, userId uuid.UUID, requestId uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "UserService.CancelFriendRequest")
+	defer span.End()
+
+	return recordError(span, s.next.CancelFriendRequest(ctx, userId, requestId))
+}
+
+func (s tracedUserService) BlockUser(ctx context.Context, userId uuid.UUID, command command.BlockUser) error {
+	ctx, span := tracer.Start(ctx, "UserService.BlockUser")
+	defer span.End()
+
+	return recordError(span, s.next.BlockUser(ctx, userId, command))
+}
+
+func (s tracedUserService) UnblockUser(ctx context.Context, userId uuid.UUID, blockedId uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "UserService.UnblockUser")
+	defer span.End()
+
+	return recordError(span, s.next.UnblockUser(ctx, userId, blockedId))
+}
+
+func (s tracedUserService) ListBlocks(ctx context.Context, userId uuid.UUID) (*query.Blocks, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ListBlocks")
+	defer span.End()
+
+	result, err := s.next.ListBlocks(ctx, userId)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) ListSessions(ctx context.Context, userId uuid.UUID, cursor string, limit uint) (*query.GameSessions, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ListSessions")
+	defer span.End()
+
+	result, err := s.next.ListSessions(ctx, userId, cursor, limit)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) Leaderboard(ctx context.Context, viewerId uuid.UUID, period domain.LeaderboardPeriod, limit uint, offset uint) (*query.Leaderboard, error) {
+	ctx, span := tracer.Start(ctx, "UserService.Leaderboard")
+	defer span.End()
+
+	result, err := s.next.Leaderboard(ctx, viewerId, period, limit, offset)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) UserRank(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID, period domain.LeaderboardPeriod, radius uint) (*query.UserRank, error) {
+	ctx, span := tracer.Start(ctx, "UserService.UserRank")
+	defer span.End()
+
+	result, err := s.next.UserRank(ctx, viewerId, userId, period, radius)
+	return result, recordError(span, err)
+}
+
+func (s tracedUserService) FriendsLeaderboard(ctx context.Context, userId uuid.UUID, period domain.LeaderboardPeriod) (*query.FriendsLeaderboard, error) {
+	ctx, span := tracer.Start(ctx, "UserService.FriendsLeaderboard")
+	defer span.End()
+
+	result, err := s.next.FriendsLeaderboard(ctx, userId, period)
+	return result, recordError(span, err)
+}
@@ -0,0 +1,42 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracedUserService(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	cases := []struct {
+		name           string
+		fakeRepository *fakeUserRepository
+		expectedStatus codes.Code
+	}{
+		{name: "success", fakeRepository: &fakeUserRepository{touchedRowsMock: 1}, expectedStatus: codes.Unset},
+		{name: "not blocked", fakeRepository: &fakeUserRepository{touchedRowsMock: 0}, expectedStatus: codes.Unset},
+		{name: "database failure", fakeRepository: &fakeUserRepository{errMock: errors.New("connection reset")}, expectedStatus: codes.Error},
+	}
+
+	for _, tc := range cases {
+		service := NewTracedUserService(&UserServiceImpl{repository: tc.fakeRepository})
+		service.UnblockUser(context.Background(), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()))
+
+		spans := recorder.Ended()
+		span := spans[len(spans)-1]
+		if span.Name() != "UserService.UnblockUser" {
+			t.Errorf("%s: expected a UserService.UnblockUser span, actual: %s", tc.name, span.Name())
+		}
+		if span.Status().Code != tc.expectedStatus {
+			t.Errorf("%s: expected the status %v, actual: %v", tc.name, tc.expectedStatus, span.Status().Code)
+		}
+	}
+}
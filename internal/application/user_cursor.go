@@ -0,0 +1,63 @@
+package application
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/domain"
+)
+
+// encodeUserCursor turns the position of a user in a listing into the opaque
+// token handed out to clients as nextCursor. The sort is part of the token,
+// so that it can't be reused with a different order.
+func encodeUserCursor(sort domain.UserSort, user *domain.User) string {
+	var value string
+	switch sort {
+	case domain.UserSortScore:
+		value = strconv.FormatInt(user.Score.Int64, 10)
+	case domain.UserSortCreated:
+		value = strconv.FormatInt(user.CreatedAt.UnixNano(), 10)
+	default:
+		value = user.Name
+	}
+	raw := fmt.Sprintf("%s:%s:%s", sort, user.Id, value)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeUserCursor(sort domain.UserSort, cursor string) (*domain.UserCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	// The name comes last, as it may contain colons itself.
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 || domain.UserSort(parts[0]) != sort {
+		return nil, ErrInvalidCursor
+	}
+	id, err := uuid.FromString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	after := domain.UserCursor{Id: id}
+	switch sort {
+	case domain.UserSortScore:
+		after.Score, err = strconv.ParseInt(parts[2], 10, 64)
+	case domain.UserSortCreated:
+		var nanos int64
+		nanos, err = strconv.ParseInt(parts[2], 10, 64)
+		after.CreatedAt = time.Unix(0, nanos)
+	default:
+		after.Name = parts[2]
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &after, nil
+}
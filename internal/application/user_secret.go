@@ -0,0 +1,56 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/application/query"
+	"game-project/internal/logging"
+)
+
+// newUserSecret generates the secret a user exchanges for access tokens,
+// along with the hash that is stored in its place. Secrets are random 256 bit
+// values, so a plain SHA-256 is enough to protect them at rest.
+func newUserSecret() (string, []byte, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(raw)
+
+	return secret, hashUserSecret(secret), nil
+}
+
+func hashUserSecret(secret string) []byte {
+	hash := sha256.Sum256([]byte(secret))
+	return hash[:]
+}
+
+func checkUserSecret(secret string, hash []byte) bool {
+	return len(hash) > 0 && subtle.ConstantTimeCompare(hashUserSecret(secret), hash) == 1
+}
+
+// ResetUserSecret replaces the secret of the user with a new one, returned
+// once. It gives users without a secret, or who lost theirs, a way to get
+// access tokens again, and invalidates the previous secret.
+func (s *UserServiceImpl) ResetUserSecret(ctx context.Context, userId uuid.UUID) (*query.UserSecret, error) {
+	secret, secretHash, err := newUserSecret()
+	if err != nil {
+		return nil, err
+	}
+	n, err := s.repository.UpdateSecret(ctx, userId, secretHash)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("could not reset the secret of user %s, error: %s", userId, err)
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errUserNotFound(userId)
+	}
+
+	return &query.UserSecret{Id: userId, Secret: secret}, nil
+}
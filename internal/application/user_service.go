@@ -1,57 +1,121 @@
 package application
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/gofrs/uuid"
-	log "github.com/sirupsen/logrus"
 
 	"game-project/internal/application/command"
 	"game-project/internal/application/query"
 	"game-project/internal/domain"
+	"game-project/internal/logging"
 )
 
 type UserService interface {
-	ListUser() []*query.User
-	CreateUser(user command.CreateUser) (*query.User, error)
-	UpdateUserState(userId uuid.UUID, command command.UpdateUserState) error
-	LoadUserState(userId uuid.UUID) (*query.UserGameStateQuery, error)
-	UpdateUserFriends(userId uuid.UUID, command command.UpdateUserFriends) (int64, error)
-	ListUserFriends(userId uuid.UUID) (*query.UserFriends, error)
+	ListUser(ctx context.Context, sort domain.UserSort, filter domain.UserFilter, cursor string, limit uint) (*query.Users, error)
+	CreateUser(ctx context.Context, user command.CreateUser) (*query.User, error)
+	Authenticate(ctx context.Context, command command.IssueToken) error
+	ResetUserSecret(ctx context.Context, userId uuid.UUID) (*query.UserSecret, error)
+	UpdateUserState(ctx context.Context, userId uuid.UUID, command command.UpdateUserState) error
+	LoadUserState(ctx context.Context, userId uuid.UUID) (*query.UserGameStateQuery, error)
+	SearchUsers(ctx context.Context, viewerId uuid.UUID, term string, limit uint, offset uint) (*query.UserSearch, error)
+	GetUserProfile(ctx context.Context, userId uuid.UUID) (*query.UserProfile, error)
+	UpdateUserProfile(ctx context.Context, userId uuid.UUID, command command.UpdateUserProfile) (*query.UserProfile, error)
+	DeleteUser(ctx context.Context, userId uuid.UUID) error
+	UpdateUserFriends(ctx context.Context, userId uuid.UUID, command command.UpdateUserFriends) (*query.FriendsUpdated, error)
+	PatchUserFriends(ctx context.Context, userId uuid.UUID, command command.UpdateUserFriends) (*query.FriendsUpdated, error)
+	RemoveUserFriend(ctx context.Context, userId uuid.UUID, friendId uuid.UUID) error
+	ListUserFriends(ctx context.Context, userId uuid.UUID) (*query.UserFriends, error)
+	SendFriendRequest(ctx context.Context, userId uuid.UUID, command command.SendFriendRequest) (*query.FriendRequest, error)
+	ListFriendRequests(ctx context.Context, userId uuid.UUID, direction domain.FriendRequestDirection) (*query.FriendRequests, error)
+	AcceptFriendRequest(ctx context.Context, userId uuid.UUID, requestId uuid.UUID) error
+	DeclineFriendRequest(ctx context.Context, userId uuid.UUID, requestId uuid.UUID) error
+	CancelFriendRequest(ctx context.Context, userId uuid.UUID, requestId uuid.UUID) error
+	BlockUser(ctx context.Context, userId uuid.UUID, command command.BlockUser) error
+	UnblockUser(ctx context.Context, userId uuid.UUID, blockedId uuid.UUID) error
+	ListBlocks(ctx context.Context, userId uuid.UUID) (*query.Blocks, error)
+	ListSessions(ctx context.Context, userId uuid.UUID, cursor string, limit uint) (*query.GameSessions, error)
+	Leaderboard(ctx context.Context, viewerId uuid.UUID, period domain.LeaderboardPeriod, limit uint, offset uint) (*query.Leaderboard, error)
+	UserRank(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID, period domain.LeaderboardPeriod, radius uint) (*query.UserRank, error)
+	FriendsLeaderboard(ctx context.Context, userId uuid.UUID, period domain.LeaderboardPeriod) (*query.FriendsLeaderboard, error)
+}
+
+var (
+	ErrInvalidCredentials = domain.UnauthorizedError("invalid_credentials", "invalid credentials")
+	ErrSecretNotSet       = domain.UnauthorizedError("secret_not_set", "the user has no secret yet, an admin has to reset it")
+	ErrNotFriends         = domain.NotFoundError("not_friends", "users are not friends")
+	ErrNotRanked          = domain.NotFoundError("not_ranked", "user has no score in the period")
+)
+
+// Config holds the tunable rules of the UserService.
+type Config struct {
+	// LeaderboardLocation is the timezone daily, weekly and monthly
+	// leaderboards reset in. UTC is used when it is nil.
+	LeaderboardLocation *time.Location
+	// RenameCooldown is how long users have to wait between name changes.
+	RenameCooldown time.Duration
+	// MaxFriendsPerRequest bounds the friend ids of a single friends
+	// update, 0 leaves them unbounded.
+	MaxFriendsPerRequest int
+	// Metrics counts the game events, they are not counted when it is nil.
+	Metrics Metrics
 }
 
 type UserServiceImpl struct {
 	repository domain.UserRepository
+	config     Config
 }
 
-func (s *UserServiceImpl) ListUser() []*query.User {
-	var queryResponse []*query.User
-	usrList := s.repository.List()
+// ListUser returns a page of the users matching the filter in the given
+// order, along with the cursor of the next page if there is one.
+func (s *UserServiceImpl) ListUser(ctx context.Context, sort domain.UserSort, filter domain.UserFilter, cursor string, limit uint) (*query.Users, error) {
+	listing := domain.UserListing{Sort: sort, Filter: filter, Limit: limit + 1}
+	var err error
+	if cursor != "" {
+		listing.After, err = decodeUserCursor(sort, cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
 
+	// Fetch one extra user to find out whether there is a next page.
+	usrList, err := s.repository.List(ctx, listing)
+	if err != nil {
+		return nil, err
+	}
+	users := query.Users{Users: make([]*query.User, 0, len(usrList))}
+	if uint(len(usrList)) > limit {
+		usrList = usrList[:limit]
+		users.NextCursor = encodeUserCursor(sort, usrList[limit-1])
+	}
 	for _, usr := range usrList {
-		qUser := query.User{
-			Id:   usr.Id,
-			Name: usr.Name,
-		}
-		queryResponse = append(queryResponse, &qUser)
+		users.Users = append(users.Users, &query.User{
+			Id:          usr.Id,
+			Name:        usr.Name,
+			GamesPlayed: usr.GamesPlayed.Int32,
+			Score:       usr.Score.Int64,
+		})
 	}
 
-	return queryResponse
+	return &users, nil
 }
 
-func (s *UserServiceImpl) ListUserFriends(userId uuid.UUID) (*query.UserFriends, error) {
-	user := s.repository.FindUser(userId)
-	if user == nil {
-		return nil, errors.New(fmt.Sprintf("no user with id %s found", userId))
+func (s *UserServiceImpl) ListUserFriends(ctx context.Context, userId uuid.UUID) (*query.UserFriends, error) {
+	if _, err := s.findUser(ctx, userId); err != nil {
+		return nil, err
 	}
 	var friendsLstRes []*query.Friend
-	friendLst := s.repository.ListFriends(userId)
+	friendLst, err := s.repository.ListFriends(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
 
 	for _, friend := range friendLst {
 		friendRes := query.Friend{
-			Id:        friend.Id,
-			Name:      friend.Name,
+			Id:   friend.Id,
+			Name: friend.Name,
 		}
 		if friend.Score.Valid {
 			friendRes.Highscore = friend.Score.Int64
@@ -62,12 +126,13 @@ func (s *UserServiceImpl) ListUserFriends(userId uuid.UUID) (*query.UserFriends,
 	return &query.UserFriends{Friends: friendsLstRes}, nil
 }
 
-
-func (s *UserServiceImpl) LoadUserState(userId uuid.UUID) (*query.UserGameStateQuery, error) {
-	usr := s.repository.FindUser(userId)
-	var err error
+func (s *UserServiceImpl) LoadUserState(ctx context.Context, userId uuid.UUID) (*query.UserGameStateQuery, error) {
+	usr, err := s.repository.FindUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
 	if usr == nil {
-		return nil, errors.New("no user found")
+		return nil, domain.NotFoundError("user_not_found", "no user found")
 	}
 	state := query.UserGameStateQuery{
 		GamesPlayed: usr.GamesPlayed.Int32,
@@ -77,38 +142,266 @@ func (s *UserServiceImpl) LoadUserState(userId uuid.UUID) (*query.UserGameStateQ
 	return &state, err
 }
 
-func (s *UserServiceImpl) CreateUser(user command.CreateUser) (*query.User, error) {
-	usr, err := s.repository.Create(user.Name)
+func (s *UserServiceImpl) CreateUser(ctx context.Context, user command.CreateUser) (*query.User, error) {
+	if err := s.validateCreateUser(user); err != nil {
+		return nil, err
+	}
+	secret, secretHash, err := newUserSecret()
+	if err != nil {
+		return nil, err
+	}
+	usr, err := s.repository.Create(ctx, user.Name, secretHash)
 	if err != nil {
-		log.Warn("error inserting user", err)
+		logging.FromContext(ctx).Warn("could not insert user, error: ", err)
+		return nil, err
 	}
+	s.metrics().UserCreated()
 
 	query := query.User{
-		Id:   usr.Id,
-		Name: usr.Name,
+		Id:     usr.Id,
+		Name:   usr.Name,
+		Secret: secret,
 	}
 
 	return &query, err
 }
 
-func (s *UserServiceImpl) UpdateUserState(userId uuid.UUID, command command.UpdateUserState) error {
-	usrInDb := s.repository.FindUser(userId)
+// Authenticate checks the secret handed out when the user was created.
+func (s *UserServiceImpl) Authenticate(ctx context.Context, command command.IssueToken) error {
+	usr, err := s.repository.FindUser(ctx, command.UserId)
+	if err != nil {
+		return err
+	}
+	if usr != nil && len(usr.SecretHash) == 0 {
+		// Users created before secrets were introduced have none.
+		return ErrSecretNotSet
+	}
+	if usr == nil || !checkUserSecret(command.Secret, usr.SecretHash) {
+		return ErrInvalidCredentials
+	}
+
+	return nil
+}
+
+func (s *UserServiceImpl) UpdateUserState(ctx context.Context, userId uuid.UUID, command command.UpdateUserState) error {
+	if err := s.validateUpdateUserState(command); err != nil {
+		return err
+	}
+	usrInDb, err := s.repository.FindUser(ctx, userId)
+	if err != nil {
+		return err
+	}
 	if usrInDb == nil {
-		log.Warnf("no user with id %s found", usrInDb.Id)
-		return errors.New("no user found")
+		logging.FromContext(ctx).Warnf("no user with id %s found", userId)
+		return domain.NotFoundError("user_not_found", "no user found")
+	}
+	session := domain.GameSession{
+		Score:    command.Score,
+		Duration: time.Duration(command.DurationMs) * time.Millisecond,
+		Metadata: command.Metadata,
+	}
+
+	if err := s.repository.UpdateUserState(ctx, userId, command.GamesPlayed, &session); err != nil {
+		return err
+	}
+	s.metrics().ScoreSubmitted()
+	if !usrInDb.Score.Valid || int64(command.Score) > usrInDb.Score.Int64 {
+		s.metrics().HighScoreImproved()
+	}
+
+	return nil
+}
+
+func (s *UserServiceImpl) ListSessions(ctx context.Context, userId uuid.UUID, cursor string, limit uint) (*query.GameSessions, error) {
+	var after *domain.SessionCursor
+	var err error
+	if cursor != "" {
+		after, err = decodeSessionCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.findUser(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra session to find out whether there is a next page.
+	sessionLst, err := s.repository.ListSessions(ctx, userId, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	sessions := query.GameSessions{Sessions: make([]*query.GameSession, 0, len(sessionLst))}
+	if uint(len(sessionLst)) > limit {
+		sessionLst = sessionLst[:limit]
+		sessions.NextCursor = encodeSessionCursor(sessionLst[limit-1])
+	}
+	for _, session := range sessionLst {
+		sessions.Sessions = append(sessions.Sessions, &query.GameSession{
+			Id:          session.Id,
+			Score:       session.Score,
+			DurationMs:  session.Duration.Milliseconds(),
+			SubmittedAt: session.SubmittedAt,
+			Metadata:    session.Metadata,
+		})
+	}
+
+	return &sessions, nil
+}
+
+// UpdateUserFriends replaces the whole friend list of the user.
+func (s *UserServiceImpl) UpdateUserFriends(ctx context.Context, userId uuid.UUID, command command.UpdateUserFriends) (*query.FriendsUpdated, error) {
+	if err := s.validateUpdateFriends(ctx, userId, command, true); err != nil {
+		return nil, err
+	}
+	return s.updateFriends(ctx, userId, domain.FriendsUpdate{Add: command.Friends, Replace: true})
+}
+
+func (s *UserServiceImpl) PatchUserFriends(ctx context.Context, userId uuid.UUID, command command.UpdateUserFriends) (*query.FriendsUpdated, error) {
+	if err := s.validateUpdateFriends(ctx, userId, command, false); err != nil {
+		return nil, err
+	}
+	return s.updateFriends(ctx, userId, domain.FriendsUpdate{Add: command.Add, Remove: command.Remove})
+}
+
+func (s *UserServiceImpl) RemoveUserFriend(ctx context.Context, userId uuid.UUID, friendId uuid.UUID) error {
+	updated, err := s.updateFriends(ctx, userId, domain.FriendsUpdate{Remove: []uuid.UUID{friendId}})
+	if err != nil {
+		return err
+	}
+	if updated.Removed == 0 {
+		return ErrNotFriends
+	}
+
+	return nil
+}
+
+func (s *UserServiceImpl) updateFriends(ctx context.Context, userId uuid.UUID, update domain.FriendsUpdate) (*query.FriendsUpdated, error) {
+	if len(update.Add) > 0 {
+		blocked, err := s.repository.BlockedAmong(ctx, userId, update.Add)
+		if err != nil {
+			return nil, err
+		}
+		if len(blocked) > 0 {
+			return nil, ErrUserBlocked
+		}
 	}
-	return s.repository.UpdateUserState(userId, command.GamesPlayed, command.Score)
+	added, removed, err := s.repository.UpdateFriends(ctx, userId, update)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("could not update friends for user id: %s, error: %s", userId, err)
+		return nil, err
+	}
+	s.metrics().FriendsAdded(added)
+
+	return &query.FriendsUpdated{Added: added, Removed: removed}, nil
 }
 
-func (s *UserServiceImpl) UpdateUserFriends(userId uuid.UUID, command command.UpdateUserFriends) (int64, error) {
-	n, err := s.repository.UpdateFriends(userId, command.Friends)
+// Leaderboard ranks every user. Users the viewer blocked are left out, pass
+// uuid.Nil for anonymous viewers.
+func (s *UserServiceImpl) Leaderboard(ctx context.Context, viewerId uuid.UUID, period domain.LeaderboardPeriod, limit uint, offset uint) (*query.Leaderboard, error) {
+	ranked, err := s.repository.Leaderboard(ctx, s.periodSince(period), viewerId, limit, offset)
 	if err != nil {
-		log.Warnf("could not insert friends for userid: %d", n)
-		return 0, err
+		return nil, err
+	}
+	entries := make([]*query.LeaderboardEntry, 0, limit)
+	for _, usr := range ranked {
+		entries = append(entries, toLeaderboardEntry(usr))
+	}
+
+	return &query.Leaderboard{
+		Period:  string(period),
+		Limit:   limit,
+		Offset:  offset,
+		Entries: entries,
+	}, nil
+}
+
+// UserRank ranks the user and their neighbours. Neighbours the viewer blocked
+// are left out, pass uuid.Nil for anonymous viewers.
+func (s *UserServiceImpl) UserRank(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID, period domain.LeaderboardPeriod, radius uint) (*query.UserRank, error) {
+	ranked, err := s.repository.LeaderboardAround(ctx, s.periodSince(period), viewerId, userId, radius)
+	if err != nil {
+		return nil, err
+	}
+	userRank := query.UserRank{Period: string(period)}
+	for _, usr := range ranked {
+		entry := toLeaderboardEntry(usr)
+		if usr.Id == userId {
+			userRank.User = entry
+		}
+		userRank.Entries = append(userRank.Entries, entry)
+	}
+	if userRank.User == nil {
+		if _, err := s.findUser(ctx, userId); err != nil {
+			return nil, err
+		}
+		// Period boards only rank the users who played in the period, and
+		// no board ranks a user without a score.
+		return nil, ErrNotRanked
+	}
+
+	return &userRank, nil
+}
+
+func (s *UserServiceImpl) FriendsLeaderboard(ctx context.Context, userId uuid.UUID, period domain.LeaderboardPeriod) (*query.FriendsLeaderboard, error) {
+	if _, err := s.findUser(ctx, userId); err != nil {
+		return nil, err
+	}
+	ranked, err := s.repository.FriendsLeaderboard(ctx, s.periodSince(period), userId)
+	if err != nil {
+		return nil, err
+	}
+
+	friendsLeaderboard := query.FriendsLeaderboard{Period: string(period)}
+	for _, usr := range ranked {
+		friendsLeaderboard.Friends = append(friendsLeaderboard.Friends, &query.FriendRank{
+			Friend: query.Friend{
+				Id:        usr.Id,
+				Name:      usr.Name,
+				Highscore: usr.Score.Int64,
+			},
+			Rank:  usr.Rank,
+			Delta: usr.Delta,
+		})
+	}
+
+	return &friendsLeaderboard, nil
+}
+
+func (s *UserServiceImpl) periodSince(period domain.LeaderboardPeriod) *time.Time {
+	loc := s.config.LeaderboardLocation
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return period.Since(time.Now(), loc)
+}
+
+// findUser loads the user, reporting a missing one as not found.
+func (s *UserServiceImpl) findUser(ctx context.Context, userId uuid.UUID) (*domain.User, error) {
+	usr, err := s.repository.FindUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if usr == nil {
+		return nil, errUserNotFound(userId)
+	}
+
+	return usr, nil
+}
+
+func errUserNotFound(userId uuid.UUID) error {
+	return domain.NotFoundError("user_not_found", fmt.Sprintf("no user with id %s found", userId))
+}
+
+func toLeaderboardEntry(usr *domain.RankedUser) *query.LeaderboardEntry {
+	return &query.LeaderboardEntry{
+		Rank:  usr.Rank,
+		Id:    usr.Id,
+		Name:  usr.Name,
+		Score: usr.Score.Int64,
 	}
-	return n, err
 }
 
-func NewUserService(r domain.UserRepository) *UserServiceImpl {
-	return &UserServiceImpl{repository: r}
+func NewUserService(r domain.UserRepository, config Config) *UserServiceImpl {
+	return &UserServiceImpl{repository: r, config: config}
 }
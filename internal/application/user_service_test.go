@@ -1,11 +1,13 @@
 package application
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/gofrs/uuid"
 
@@ -41,10 +43,14 @@ func TestUserServiceImpl_CreateUser(t *testing.T) {
 
 	for _, tc := range cases {
 		service := UserServiceImpl{repository: tc.fakeRepository}
-		result, err := service.CreateUser(tc.command)
+		result, err := service.CreateUser(context.Background(), tc.command)
 		if (err != tc.expectedErr) {
 			t.Errorf(fmt.Sprintf("expected err: %s, actual err: %s", err, tc.expectedErr))
 		}
+		if result.Secret == "" {
+			t.Error("expected a secret to be issued on creation")
+		}
+		result.Secret = ""
 		if (!reflect.DeepEqual(result, tc.expectedResult)) {
 			t.Errorf(fmt.Sprintf("expected result: %v, actual: %v", tc.expectedResult, result))
 		}
@@ -52,9 +58,12 @@ func TestUserServiceImpl_CreateUser(t *testing.T) {
 }
 
 func TestUserServiceImpl_ListUser(t *testing.T) {
+	firstId, _ := uuid.NewV4()
+	secondId, _ := uuid.NewV4()
 	cases := []struct {
 		fakeRepository *fakeUserRepository
-		expectedResult []*query.User
+		limit uint
+		expectedResult *query.Users
 		expectedErr error
 	}{
 		{
@@ -67,23 +76,90 @@ func TestUserServiceImpl_ListUser(t *testing.T) {
 				},
 				errMock:         nil,
 			},
-			expectedResult: []*query.User{
-				{
-					Id:   uuid.UUID{},
-					Name: "Jake",
+			limit: 10,
+			expectedResult: &query.Users{
+				Users: []*query.User{
+					{
+						Id:   uuid.UUID{},
+						Name: "Jake",
+					},
 				},
 			},
 			expectedErr:    nil,
 		},
+		{
+			fakeRepository: &fakeUserRepository{
+				listMock:        []*domain.User{
+					{
+						Id:          firstId,
+						Name:        "Jake",
+						Score:       sql.NullInt64{Int64: 30, Valid: true},
+					},
+					{
+						Id:          secondId,
+						Name:        "Finn",
+					},
+				},
+			},
+			limit: 1,
+			expectedResult: &query.Users{
+				Users: []*query.User{
+					{
+						Id:    firstId,
+						Name:  "Jake",
+						Score: 30,
+					},
+				},
+				NextCursor: encodeUserCursor(domain.UserSortName, &domain.User{Id: firstId, Name: "Jake"}),
+			},
+			expectedErr:    nil,
+		},
 	}
 
 	for _, tc := range cases {
 		service := UserServiceImpl{repository: tc.fakeRepository}
-		result := service.ListUser()
+		result, err := service.ListUser(context.Background(), domain.UserSortName, domain.UserFilter{}, "", tc.limit)
+		if err != tc.expectedErr {
+			t.Errorf("expected err %v, actual: %v", tc.expectedErr, err)
+		}
 		if !reflect.DeepEqual(result, tc.expectedResult) {
 			t.Errorf("expected result: %v , actual: %v", tc.expectedResult, result)
 		}
 	}
+
+	service := UserServiceImpl{repository: &fakeUserRepository{}}
+	cursor := encodeUserCursor(domain.UserSortName, &domain.User{Id: firstId, Name: "Jake"})
+	if _, err := service.ListUser(context.Background(), domain.UserSortScore, domain.UserFilter{}, cursor, 10); err != ErrInvalidCursor {
+		t.Errorf("reusing a cursor with another sort: expected err %v, actual: %v", ErrInvalidCursor, err)
+	}
+}
+
+func TestUserCursor(t *testing.T) {
+	id, _ := uuid.NewV4()
+	user := &domain.User{
+		Id:        id,
+		Name:      "Jake: the dog",
+		Score:     sql.NullInt64{Int64: 42, Valid: true},
+		CreatedAt: time.Unix(0, 1630000000123456789),
+	}
+	cases := []struct {
+		sort     domain.UserSort
+		expected *domain.UserCursor
+	}{
+		{sort: domain.UserSortName, expected: &domain.UserCursor{Id: id, Name: "Jake: the dog"}},
+		{sort: domain.UserSortScore, expected: &domain.UserCursor{Id: id, Score: 42}},
+		{sort: domain.UserSortCreated, expected: &domain.UserCursor{Id: id, CreatedAt: user.CreatedAt}},
+	}
+
+	for _, tc := range cases {
+		after, err := decodeUserCursor(tc.sort, encodeUserCursor(tc.sort, user))
+		if err != nil {
+			t.Fatalf("%s: unexpected err %v", tc.sort, err)
+		}
+		if !reflect.DeepEqual(after, tc.expected) {
+			t.Errorf("%s: expected cursor %+v, actual: %+v", tc.sort, tc.expected, after)
+		}
+	}
 }
 
 func TestUserServiceImpl_ListUserFriends(t *testing.T) {
@@ -162,7 +238,7 @@ func TestUserServiceImpl_ListUserFriends(t *testing.T) {
 
 	for _, tc := range cases {
 		service := UserServiceImpl{repository: tc.fakeRepository}
-		response, err := service.ListUserFriends(uuid.UUID{})
+		response, err := service.ListUserFriends(context.Background(), uuid.UUID{})
 
 		if tc.expectedErr != nil {
 			if err.Error() != tc.expectedErr.Error() {
@@ -187,34 +263,513 @@ func TestUserServiceImpl_ListUserFriends(t *testing.T) {
 	}
 }
 
+func TestUserServiceImpl_UserRank(t *testing.T) {
+	userId, _ := uuid.NewV4()
+	cases := []struct {
+		fakeRepository *fakeUserRepository
+		period         domain.LeaderboardPeriod
+		expectedResult *query.UserRank
+		expectedErr    error
+	}{
+		{
+			fakeRepository: &fakeUserRepository{
+				leaderboardMock: []*domain.RankedUser{
+					{User: domain.User{Name: "Jessica", Score: sql.NullInt64{Int64: 300, Valid: true}}, Rank: 1},
+					{User: domain.User{Id: userId, Name: "Don", Score: sql.NullInt64{Int64: 200, Valid: true}}, Rank: 2},
+					{User: domain.User{Name: "Paul"}, Rank: 3},
+				},
+			},
+			expectedResult: &query.UserRank{
+				Period: "all",
+				User: &query.LeaderboardEntry{Rank: 2, Id: userId, Name: "Don", Score: 200},
+				Entries: []*query.LeaderboardEntry{
+					{Rank: 1, Name: "Jessica", Score: 300},
+					{Rank: 2, Id: userId, Name: "Don", Score: 200},
+					{Rank: 3, Name: "Paul", Score: 0},
+				},
+			},
+			period:      domain.PeriodAllTime,
+			expectedErr: nil,
+		},
+		{
+			fakeRepository: &fakeUserRepository{},
+			period:         domain.PeriodAllTime,
+			expectedResult: nil,
+			expectedErr:    errors.New(fmt.Sprintf("no user with id %s found", userId)),
+		},
+		{
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{Id: userId, Name: "Don"}},
+			period:         domain.PeriodDaily,
+			expectedResult: nil,
+			expectedErr:    ErrNotRanked,
+		},
+		{
+			// The user exists but has no score yet.
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{Id: userId, Name: "Don"}},
+			period:         domain.PeriodAllTime,
+			expectedResult: nil,
+			expectedErr:    ErrNotRanked,
+		},
+	}
+
+	for _, tc := range cases {
+		service := UserServiceImpl{repository: tc.fakeRepository}
+		response, err := service.UserRank(context.Background(), uuid.Nil, userId, tc.period, 1)
+
+		if tc.expectedErr != nil {
+			if err == nil || err.Error() != tc.expectedErr.Error() {
+				t.Errorf("expected err %s, actual: %s", tc.expectedErr, err)
+			}
+		} else if err != nil {
+			t.Errorf("expected no err, actual: %s", err)
+		}
+		if !reflect.DeepEqual(response, tc.expectedResult) {
+			t.Errorf("expected response to be %v, actual: %v", tc.expectedResult, response)
+		}
+	}
+}
+
+func TestUserServiceImpl_FriendsLeaderboard(t *testing.T) {
+	cases := []struct {
+		fakeRepository *fakeUserRepository
+		expectedResult *query.FriendsLeaderboard
+		expectedErr    error
+	}{
+		{
+			fakeRepository: &fakeUserRepository{
+				findUserMock: &domain.User{Name: "Don"},
+				leaderboardMock: []*domain.RankedUser{
+					{User: domain.User{Name: "Jessica", Score: sql.NullInt64{Int64: 300, Valid: true}}, Rank: 1, Delta: 100},
+					{User: domain.User{Name: "Don", Score: sql.NullInt64{Int64: 200, Valid: true}}, Rank: 2},
+					{User: domain.User{Name: "Paul"}, Rank: 3, Delta: -200},
+				},
+			},
+			expectedResult: &query.FriendsLeaderboard{
+				Period: "weekly",
+				Friends: []*query.FriendRank{
+					{Friend: query.Friend{Name: "Jessica", Highscore: 300}, Rank: 1, Delta: 100},
+					{Friend: query.Friend{Name: "Don", Highscore: 200}, Rank: 2},
+					{Friend: query.Friend{Name: "Paul"}, Rank: 3, Delta: -200},
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			fakeRepository: &fakeUserRepository{findUserMock: nil},
+			expectedResult: nil,
+			expectedErr:    errors.New(fmt.Sprintf("no user with id %s found", uuid.UUID{})),
+		},
+	}
+
+	for _, tc := range cases {
+		service := UserServiceImpl{repository: tc.fakeRepository}
+		response, err := service.FriendsLeaderboard(context.Background(), uuid.UUID{}, domain.PeriodWeekly)
+
+		if tc.expectedErr != nil {
+			if err == nil || err.Error() != tc.expectedErr.Error() {
+				t.Errorf("expected err %s, actual: %s", tc.expectedErr, err)
+			}
+		} else if err != nil {
+			t.Errorf("expected no err, actual: %s", err)
+		}
+		if !reflect.DeepEqual(response, tc.expectedResult) {
+			t.Errorf("expected response to be %v, actual: %v", tc.expectedResult, response)
+		}
+	}
+}
+
+func TestUserServiceImpl_ListSessions(t *testing.T) {
+	submittedAt := time.Date(2021, 8, 30, 12, 0, 0, 0, time.UTC)
+	fakeRepository := &fakeUserRepository{
+		findUserMock: &domain.User{Name: "Don"},
+		sessionsMock: []*domain.GameSession{
+			{Id: 3, Score: 300, Duration: 2 * time.Second, SubmittedAt: submittedAt},
+			{Id: 2, Score: 100, SubmittedAt: submittedAt.Add(-time.Hour)},
+			{Id: 1, Score: 200, SubmittedAt: submittedAt.Add(-2 * time.Hour)},
+		},
+	}
+	service := UserServiceImpl{repository: fakeRepository}
+
+	page, err := service.ListSessions(context.Background(), uuid.UUID{}, "", 2)
+	if err != nil {
+		t.Fatalf("expected no err, actual: %s", err)
+	}
+	expectedSessions := []*query.GameSession{
+		{Id: 3, Score: 300, DurationMs: 2000, SubmittedAt: submittedAt},
+		{Id: 2, Score: 100, SubmittedAt: submittedAt.Add(-time.Hour)},
+	}
+	if !reflect.DeepEqual(page.Sessions, expectedSessions) {
+		t.Errorf("expected sessions %v, actual: %v", expectedSessions, page.Sessions)
+	}
+	cursor, err := decodeSessionCursor(page.NextCursor)
+	if err != nil {
+		t.Fatalf("expected a valid next cursor, actual err: %s", err)
+	}
+	if cursor.Id != 2 || !cursor.SubmittedAt.Equal(submittedAt.Add(-time.Hour)) {
+		t.Errorf("expected cursor to point at session 2, actual: %+v", cursor)
+	}
+
+	page, err = service.ListSessions(context.Background(), uuid.UUID{}, "", 3)
+	if err != nil || page.NextCursor != "" {
+		t.Errorf("expected last page without next cursor, actual: %q, err: %v", page.NextCursor, err)
+	}
+
+	_, err = service.ListSessions(context.Background(), uuid.UUID{}, "not a cursor", 2)
+	if !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected err %s, actual: %v", ErrInvalidCursor, err)
+	}
+}
+
+func TestUserServiceImpl_UpdateUserState(t *testing.T) {
+	cases := []struct {
+		fakeRepository *fakeUserRepository
+		expectedErr    error
+	}{
+		{
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{Name: "Don"}},
+			expectedErr:    nil,
+		},
+		{
+			fakeRepository: &fakeUserRepository{findUserMock: nil},
+			expectedErr:    errors.New("no user found"),
+		},
+	}
+
+	for _, tc := range cases {
+		service := UserServiceImpl{repository: tc.fakeRepository}
+		err := service.UpdateUserState(context.Background(), uuid.UUID{}, command.UpdateUserState{GamesPlayed: 1, Score: 100})
+		if fmt.Sprint(err) != fmt.Sprint(tc.expectedErr) {
+			t.Errorf("expected err %v, actual: %v", tc.expectedErr, err)
+		}
+	}
+}
+
+func TestUserServiceImpl_Authenticate(t *testing.T) {
+	secret, secretHash, err := newUserSecret()
+	if err != nil {
+		t.Fatalf("expected no err, actual: %s", err)
+	}
+	cases := []struct {
+		fakeRepository *fakeUserRepository
+		secret         string
+		expectedErr    error
+	}{
+		{
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{Name: "Don", SecretHash: secretHash}},
+			secret:         secret,
+			expectedErr:    nil,
+		},
+		{
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{Name: "Don", SecretHash: secretHash}},
+			secret:         "wrong",
+			expectedErr:    ErrInvalidCredentials,
+		},
+		{
+			fakeRepository: &fakeUserRepository{findUserMock: &domain.User{Name: "Legacy"}},
+			secret:         "",
+			expectedErr:    ErrSecretNotSet,
+		},
+		{
+			fakeRepository: &fakeUserRepository{findUserMock: nil},
+			secret:         secret,
+			expectedErr:    ErrInvalidCredentials,
+		},
+	}
+
+	for _, tc := range cases {
+		service := UserServiceImpl{repository: tc.fakeRepository}
+		err := service.Authenticate(context.Background(), command.IssueToken{Secret: tc.secret})
+		if err != tc.expectedErr {
+			t.Errorf("expected err %v, actual: %v", tc.expectedErr, err)
+		}
+	}
+}
+
+func TestUserServiceImpl_ResetUserSecret(t *testing.T) {
+	userId := uuid.Must(uuid.NewV4())
+	service := UserServiceImpl{repository: &fakeUserRepository{touchedRowsMock: 1}}
+	secret, err := service.ResetUserSecret(context.Background(), userId)
+	if err != nil {
+		t.Fatalf("expected no err, actual: %s", err)
+	}
+	if secret.Id != userId || secret.Secret == "" {
+		t.Errorf("expected a new secret for user %s, actual: %+v", userId, secret)
+	}
+
+	service = UserServiceImpl{repository: &fakeUserRepository{touchedRowsMock: 0}}
+	_, err = service.ResetUserSecret(context.Background(), userId)
+	if fmt.Sprint(err) != fmt.Sprint(errUserNotFound(userId)) {
+		t.Errorf("expected err %v, actual: %v", errUserNotFound(userId), err)
+	}
+}
+
+func TestUserServiceImpl_RemoveUserFriend(t *testing.T) {
+	cases := []struct {
+		fakeRepository *fakeUserRepository
+		expectedErr    error
+	}{
+		{fakeRepository: &fakeUserRepository{touchedRowsMock: 1}, expectedErr: nil},
+		{fakeRepository: &fakeUserRepository{touchedRowsMock: 0}, expectedErr: ErrNotFriends},
+	}
+
+	for _, tc := range cases {
+		service := UserServiceImpl{repository: tc.fakeRepository}
+		err := service.RemoveUserFriend(context.Background(), uuid.UUID{}, uuid.UUID{})
+		if err != tc.expectedErr {
+			t.Errorf("expected err %v, actual: %v", tc.expectedErr, err)
+		}
+	}
+}
+
+func TestUserServiceImpl_Cancellation(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	fakeRepository := &fakeUserRepository{
+		findUserMock: &domain.User{Id: uuid.UUID{}, Name: "Jake", SecretHash: hashUserSecret("secret")},
+		touchedRowsMock: 1,
+	}
+	service := UserServiceImpl{repository: fakeRepository}
+	cases := []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{name: "LoadUserState", call: func(ctx context.Context) error {
+			_, err := service.LoadUserState(ctx, uuid.UUID{})
+			return err
+		}},
+		{name: "UpdateUserState", call: func(ctx context.Context) error {
+			return service.UpdateUserState(ctx, uuid.UUID{}, command.UpdateUserState{GamesPlayed: 1, Score: 10})
+		}},
+		{name: "ListUserFriends", call: func(ctx context.Context) error {
+			_, err := service.ListUserFriends(ctx, uuid.UUID{})
+			return err
+		}},
+		{name: "Leaderboard", call: func(ctx context.Context) error {
+			_, err := service.Leaderboard(ctx, uuid.UUID{}, domain.PeriodAllTime, 10, 0)
+			return err
+		}},
+		{name: "Authenticate", call: func(ctx context.Context) error {
+			return service.Authenticate(ctx, command.IssueToken{UserId: uuid.UUID{}, Secret: "secret"})
+		}},
+		{name: "DeleteUser", call: func(ctx context.Context) error {
+			return service.DeleteUser(ctx, uuid.UUID{})
+		}},
+	}
+
+	for _, tc := range cases {
+		if err := tc.call(context.Background()); err != nil {
+			t.Errorf("%s: expected no err with a live context, actual: %v", tc.name, err)
+		}
+		if err := tc.call(canceled); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected err %v, actual: %v", tc.name, context.Canceled, err)
+		}
+		if err := tc.call(expired); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("%s: expected err %v, actual: %v", tc.name, context.DeadlineExceeded, err)
+		}
+	}
+}
+
+func TestUserServiceImpl_ReadErrors(t *testing.T) {
+	readErr := domain.UnavailableError("database_unavailable", "the database is unavailable")
+	fakeRepository := &fakeUserRepository{findUserMock: &domain.User{Name: "Jake"}, errMock: readErr}
+	service := UserServiceImpl{repository: fakeRepository}
+	cases := []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{name: "LoadUserState", call: func(ctx context.Context) error {
+			_, err := service.LoadUserState(ctx, uuid.UUID{})
+			return err
+		}},
+		{name: "ListUserFriends", call: func(ctx context.Context) error {
+			_, err := service.ListUserFriends(ctx, uuid.UUID{})
+			return err
+		}},
+		{name: "Leaderboard", call: func(ctx context.Context) error {
+			_, err := service.Leaderboard(ctx, uuid.UUID{}, domain.PeriodAllTime, 10, 0)
+			return err
+		}},
+		{name: "FriendsLeaderboard", call: func(ctx context.Context) error {
+			_, err := service.FriendsLeaderboard(ctx, uuid.UUID{}, domain.PeriodAllTime)
+			return err
+		}},
+		{name: "ListUser", call: func(ctx context.Context) error {
+			_, err := service.ListUser(ctx, domain.UserSortName, domain.UserFilter{}, "", 10)
+			return err
+		}},
+		{name: "SearchUsers", call: func(ctx context.Context) error {
+			_, err := service.SearchUsers(ctx, uuid.UUID{}, "jake", 10, 0)
+			return err
+		}},
+		{name: "GetUserProfile", call: func(ctx context.Context) error {
+			_, err := service.GetUserProfile(ctx, uuid.UUID{})
+			return err
+		}},
+		{name: "ListSessions", call: func(ctx context.Context) error {
+			_, err := service.ListSessions(ctx, uuid.UUID{}, "", 10)
+			return err
+		}},
+		{name: "ListBlocks", call: func(ctx context.Context) error {
+			_, err := service.ListBlocks(ctx, uuid.UUID{})
+			return err
+		}},
+		{name: "ListFriendRequests", call: func(ctx context.Context) error {
+			_, err := service.ListFriendRequests(ctx, uuid.UUID{}, domain.FriendRequestIncoming)
+			return err
+		}},
+		{name: "AcceptFriendRequest", call: func(ctx context.Context) error {
+			return service.AcceptFriendRequest(ctx, uuid.UUID{}, uuid.UUID{})
+		}},
+		{name: "Authenticate", call: func(ctx context.Context) error {
+			return service.Authenticate(ctx, command.IssueToken{UserId: uuid.UUID{}, Secret: "secret"})
+		}},
+	}
+
+	for _, tc := range cases {
+		if err := tc.call(context.Background()); !errors.Is(err, readErr) {
+			t.Errorf("%s: expected err %v, actual: %v", tc.name, readErr, err)
+		}
+	}
+}
+
 type fakeUserRepository struct {
 	listMock []*domain.User
 	createMock *domain.User
 	findUserMock *domain.User
 	listFriendsMock []*domain.User
+	leaderboardMock []*domain.RankedUser
+	sessionsMock []*domain.GameSession
+	friendRequestMock *domain.FriendRequest
+	friendRequestsMock []*domain.FriendRequest
+	touchedRowsMock int64
+	blockedMock []*domain.User
+	blockedAmongMock []uuid.UUID
+	missingUsersMock []uuid.UUID
+	nameHistoryMock []*domain.NameChange
 	errMock error
 }
 
-func (f fakeUserRepository) List() []*domain.User {
-	return f.listMock
+func (f fakeUserRepository) List(ctx context.Context, listing domain.UserListing) ([]*domain.User, error) {
+	if uint(len(f.listMock)) > listing.Limit {
+		return f.listMock[:listing.Limit], f.errFor(ctx)
+	}
+	return f.listMock, f.errFor(ctx)
+}
+
+func (f fakeUserRepository) Create(ctx context.Context, uName string, secretHash []byte) (*domain.User, error) {
+	return f.createMock, f.errFor(ctx)
+}
+
+func (f fakeUserRepository) UpdateSecret(ctx context.Context, userId uuid.UUID, secretHash []byte) (touchedRows int64, err error) {
+	return f.touchedRowsMock, f.errFor(ctx)
+}
+
+func (f fakeUserRepository) UpdateUserState(ctx context.Context, userId uuid.UUID, gamesPlayed uint8, session *domain.GameSession) error {
+	return f.errFor(ctx)
+}
+
+func (f fakeUserRepository) ListSessions(ctx context.Context, userId uuid.UUID, cursor *domain.SessionCursor, limit uint) ([]*domain.GameSession, error) {
+	if uint(len(f.sessionsMock)) > limit {
+		return f.sessionsMock[:limit], f.errFor(ctx)
+	}
+	return f.sessionsMock, f.errFor(ctx)
+}
+
+func (f fakeUserRepository) FindUser(ctx context.Context, userId uuid.UUID) (*domain.User, error) {
+	if err := f.errFor(ctx); err != nil {
+		return nil, err
+	}
+	return f.findUserMock, nil
+}
+
+func (f fakeUserRepository) ExistingUsers(ctx context.Context, userIds []uuid.UUID) ([]uuid.UUID, error) {
+	var existing []uuid.UUID
+	for _, id := range userIds {
+		if !containsUUID(f.missingUsersMock, id) {
+			existing = append(existing, id)
+		}
+	}
+	return existing, f.errFor(ctx)
 }
 
-func (f fakeUserRepository) Create(uName string) (*domain.User, error) {
-	return f.createMock, f.errMock
+func (f fakeUserRepository) Search(ctx context.Context, term string, viewerId uuid.UUID, limit uint, offset uint) ([]*domain.User, error) {
+	return f.listMock, f.errFor(ctx)
 }
 
-func (f fakeUserRepository) UpdateUserState(userId uuid.UUID, gamesPlayed uint8, score uint) error {
-	panic("implement me")
+func (f fakeUserRepository) UpdateProfile(ctx context.Context, user *domain.User) error {
+	return f.errFor(ctx)
 }
 
-func (f fakeUserRepository) FindUser(userId uuid.UUID) *domain.User {
-	return f.findUserMock
+func (f fakeUserRepository) ListNameHistory(ctx context.Context, userId uuid.UUID) ([]*domain.NameChange, error) {
+	return f.nameHistoryMock, f.errFor(ctx)
 }
 
-func (f fakeUserRepository) UpdateFriends(userId uuid.UUID, friendLst []uuid.UUID) (touchedRows int64, err error) {
-	panic("implement me")
+func (f fakeUserRepository) Delete(ctx context.Context, userId uuid.UUID) (touchedRows int64, err error) {
+	return f.touchedRowsMock, f.errFor(ctx)
 }
 
-func (f fakeUserRepository) ListFriends(userId uuid.UUID) []*domain.User {
-	return f.listFriendsMock
+func (f fakeUserRepository) UpdateFriends(ctx context.Context, userId uuid.UUID, update domain.FriendsUpdate) (added int64, removed int64, err error) {
+	if f.errFor(ctx) != nil {
+		return 0, 0, f.errFor(ctx)
+	}
+	return int64(len(update.Add)), f.touchedRowsMock, nil
+}
+
+func (f fakeUserRepository) ListFriends(ctx context.Context, userId uuid.UUID) ([]*domain.User, error) {
+	return f.listFriendsMock, f.errFor(ctx)
+}
+
+func (f fakeUserRepository) Leaderboard(ctx context.Context, since *time.Time, viewerId uuid.UUID, limit uint, offset uint) ([]*domain.RankedUser, error) {
+	return f.leaderboardMock, f.errFor(ctx)
+}
+
+func (f fakeUserRepository) LeaderboardAround(ctx context.Context, since *time.Time, viewerId uuid.UUID, userId uuid.UUID, radius uint) ([]*domain.RankedUser, error) {
+	return f.leaderboardMock, f.errFor(ctx)
+}
+
+func (f fakeUserRepository) FriendsLeaderboard(ctx context.Context, since *time.Time, userId uuid.UUID) ([]*domain.RankedUser, error) {
+	return f.leaderboardMock, f.errFor(ctx)
+}
+
+func (f fakeUserRepository) CreateFriendRequest(ctx context.Context, fromUserId uuid.UUID, toUserId uuid.UUID) (*domain.FriendRequest, error) {
+	return f.friendRequestMock, f.errFor(ctx)
+}
+
+func (f fakeUserRepository) FindFriendRequest(ctx context.Context, requestId uuid.UUID) (*domain.FriendRequest, error) {
+	return f.friendRequestMock, f.errFor(ctx)
+}
+
+func (f fakeUserRepository) ListFriendRequests(ctx context.Context, userId uuid.UUID, direction domain.FriendRequestDirection) ([]*domain.FriendRequest, error) {
+	return f.friendRequestsMock, f.errFor(ctx)
+}
+
+func (f fakeUserRepository) ResolveFriendRequest(ctx context.Context, requestId uuid.UUID, status domain.FriendRequestStatus) (touchedRows int64, err error) {
+	return f.touchedRowsMock, f.errFor(ctx)
+}
+
+func (f fakeUserRepository) BlockUser(ctx context.Context, userId uuid.UUID, blockedId uuid.UUID) (touchedRows int64, err error) {
+	return f.touchedRowsMock, f.errFor(ctx)
+}
+
+func (f fakeUserRepository) UnblockUser(ctx context.Context, userId uuid.UUID, blockedId uuid.UUID) (touchedRows int64, err error) {
+	return f.touchedRowsMock, f.errFor(ctx)
+}
+
+func (f fakeUserRepository) ListBlocks(ctx context.Context, userId uuid.UUID) ([]*domain.User, error) {
+	return f.blockedMock, f.errFor(ctx)
+}
+
+func (f fakeUserRepository) BlockedAmong(ctx context.Context, userId uuid.UUID, otherIds []uuid.UUID) ([]uuid.UUID, error) {
+	return f.blockedAmongMock, f.errFor(ctx)
+}
+
+// errFor fails like the real repository once the context is done.
+func (f fakeUserRepository) errFor(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.errMock
 }
@@ -0,0 +1,187 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/application/command"
+	"game-project/internal/domain"
+)
+
+const (
+	minNameLength      = 3
+	maxNameLength      = 32
+	maxSessionDuration = 24 * time.Hour
+	maxMetadataBytes   = 4096
+	maxAvatarUrlLength = 2048
+	maxBioLength       = 500
+)
+
+// rule is a single check of a command field. The field error is reported
+// when valid is false.
+type rule struct {
+	field   string
+	message string
+	valid   bool
+}
+
+// fieldErrors collects the messages of every rule that does not hold.
+func fieldErrors(rules ...rule) []domain.FieldError {
+	var fields []domain.FieldError
+	for _, r := range rules {
+		if !r.valid {
+			fields = append(fields, domain.FieldError{Field: r.field, Message: r.message})
+		}
+	}
+	return fields
+}
+
+func invalidCommand(fields []domain.FieldError) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return domain.ValidationError("invalid_command", "the request has invalid fields", fields...)
+}
+
+func nameRules(field string, name string) []rule {
+	length := utf8.RuneCountInString(name)
+	return []rule{
+		{field, fmt.Sprintf("must be between %d and %d characters long", minNameLength, maxNameLength),
+			length >= minNameLength && length <= maxNameLength},
+		{field, "may only contain letters, digits, spaces and the characters _ - .", strings.IndexFunc(name, invalidNameRune) < 0},
+		{field, "must not start or end with a space", strings.TrimSpace(name) == name},
+	}
+}
+
+func invalidNameRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(" _-.", r)
+}
+
+func (s *UserServiceImpl) validateCreateUser(command command.CreateUser) error {
+	return invalidCommand(fieldErrors(nameRules("name", command.Name)...))
+}
+
+// validateUpdateUserProfile checks the fields present in the command. An
+// empty country, avatar URL or bio clears it and is always valid.
+func (s *UserServiceImpl) validateUpdateUserProfile(command command.UpdateUserProfile) error {
+	var rules []rule
+	if command.Name != nil {
+		rules = append(rules, nameRules("name", *command.Name)...)
+	}
+	if command.Country != nil && *command.Country != "" {
+		rules = append(rules, rule{"country", "must be an ISO 3166-1 alpha-2 code, such as US", validCountryCode(*command.Country)})
+	}
+	if command.AvatarUrl != nil && *command.AvatarUrl != "" {
+		rules = append(rules,
+			rule{"avatarUrl", fmt.Sprintf("must not exceed %d characters", maxAvatarUrlLength),
+				utf8.RuneCountInString(*command.AvatarUrl) <= maxAvatarUrlLength},
+			rule{"avatarUrl", "must be an http or https URL", validAvatarUrl(*command.AvatarUrl)},
+		)
+	}
+	if command.Bio != nil {
+		rules = append(rules, rule{"bio", fmt.Sprintf("must not exceed %d characters", maxBioLength),
+			utf8.RuneCountInString(*command.Bio) <= maxBioLength})
+	}
+
+	return invalidCommand(fieldErrors(rules...))
+}
+
+func validCountryCode(code string) bool {
+	return len(code) == 2 && code[0] >= 'A' && code[0] <= 'Z' && code[1] >= 'A' && code[1] <= 'Z'
+}
+
+func validAvatarUrl(raw string) bool {
+	parsed, err := url.Parse(raw)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+func (s *UserServiceImpl) validateUpdateUserState(command command.UpdateUserState) error {
+	metadata := bytes.TrimSpace(command.Metadata)
+	return invalidCommand(fieldErrors(
+		rule{"score", fmt.Sprintf("must not exceed %d", math.MaxInt32), command.Score <= math.MaxInt32},
+		rule{"durationMs", fmt.Sprintf("must not exceed %d", maxSessionDuration.Milliseconds()),
+			time.Duration(command.DurationMs)*time.Millisecond <= maxSessionDuration},
+		rule{"metadata", fmt.Sprintf("must not exceed %d bytes", maxMetadataBytes), len(metadata) <= maxMetadataBytes},
+		rule{"metadata", "must be a JSON object", len(metadata) == 0 || metadata[0] == '{' || bytes.Equal(metadata, []byte("null"))},
+	))
+}
+
+// validateUpdateFriends checks the friend ids of a friends update: none may
+// be the user, appear twice, be both added and removed or, when added, not
+// belong to an existing user. A replacing update only takes the friends
+// field, which is required, an incremental one only add and remove.
+func (s *UserServiceImpl) validateUpdateFriends(ctx context.Context, userId uuid.UUID, command command.UpdateUserFriends, replace bool) error {
+	lists := []struct {
+		field string
+		ids   []uuid.UUID
+	}{{"friends", command.Friends}, {"add", command.Add}, {"remove", command.Remove}}
+
+	var fields []domain.FieldError
+	if replace {
+		fields = fieldErrors(
+			rule{"friends", "is required, an empty list removes every friend", command.Friends != nil},
+			rule{"add", "is only allowed when patching the friend list", command.Add == nil},
+			rule{"remove", "is only allowed when patching the friend list", command.Remove == nil},
+		)
+	} else {
+		fields = fieldErrors(rule{"friends", "is only allowed when replacing the friend list", command.Friends == nil})
+	}
+	var added []uuid.UUID
+	total := 0
+	for _, list := range lists {
+		total += len(list.ids)
+		seen := make(map[uuid.UUID]bool, len(list.ids))
+		for i, id := range list.ids {
+			field := fmt.Sprintf("%s[%d]", list.field, i)
+			fields = append(fields, fieldErrors(
+				rule{field, "must not be empty", id != uuid.Nil},
+				rule{field, "must not be the user", id != userId},
+				rule{field, "must not be repeated", !seen[id]},
+				rule{field, "must not be both added and removed", list.field != "remove" || !containsUUID(command.Add, id)},
+			)...)
+			seen[id] = true
+		}
+		if list.field != "remove" {
+			added = append(added, list.ids...)
+		}
+	}
+	if max := s.config.MaxFriendsPerRequest; max > 0 && total > max {
+		// Too many ids to look them up, skip the existence check.
+		fields = append(fields, domain.FieldError{Field: "friends", Message: fmt.Sprintf("must not list more than %d users", max)})
+		return invalidCommand(fields)
+	}
+
+	if len(added) > 0 {
+		existing, err := s.repository.ExistingUsers(ctx, added)
+		if err != nil {
+			return err
+		}
+		for _, list := range lists[:2] {
+			for i, id := range list.ids {
+				if id != uuid.Nil && id != userId && !containsUUID(existing, id) {
+					fields = append(fields, domain.FieldError{Field: fmt.Sprintf("%s[%d]", list.field, i), Message: "no user with this id exists"})
+				}
+			}
+		}
+	}
+
+	return invalidCommand(fields)
+}
+
+func containsUUID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,271 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/application/command"
+	"game-project/internal/domain"
+)
+
+// validationFields returns the field errors of err, or nil when err is not
+// a validation error.
+func validationFields(err error) []domain.FieldError {
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) || !errors.Is(err, domain.ErrValidation) {
+		return nil
+	}
+	return domainErr.Fields
+}
+
+func TestUserServiceImpl_CreateUserValidation(t *testing.T) {
+	cases := []struct {
+		name           string
+		userName       string
+		expectedFields []domain.FieldError
+	}{
+		{name: "valid name", userName: "Jake the Dog-2"},
+		{name: "accented name", userName: "Joaquín"},
+		{
+			name:     "empty name",
+			userName: "",
+			expectedFields: []domain.FieldError{
+				{Field: "name", Message: "must be between 3 and 32 characters long"},
+			},
+		},
+		{
+			name:     "too long and padded name",
+			userName: " " + strings.Repeat("a", 40),
+			expectedFields: []domain.FieldError{
+				{Field: "name", Message: "must be between 3 and 32 characters long"},
+				{Field: "name", Message: "must not start or end with a space"},
+			},
+		},
+		{
+			name:     "invalid characters",
+			userName: "<script>",
+			expectedFields: []domain.FieldError{
+				{Field: "name", Message: "may only contain letters, digits, spaces and the characters _ - ."},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		service := UserServiceImpl{repository: &fakeUserRepository{createMock: &domain.User{Name: tc.userName}}}
+		_, err := service.CreateUser(context.Background(), command.CreateUser{Name: tc.userName})
+		if tc.expectedFields == nil && err != nil {
+			t.Errorf("%s: expected no error, actual: %v", tc.name, err)
+		}
+		if fields := validationFields(err); !reflect.DeepEqual(fields, tc.expectedFields) {
+			t.Errorf("%s: expected fields %+v, actual: %+v", tc.name, tc.expectedFields, fields)
+		}
+	}
+}
+
+func TestUserServiceImpl_UpdateUserStateValidation(t *testing.T) {
+	cases := []struct {
+		name           string
+		command        command.UpdateUserState
+		expectedFields []domain.FieldError
+	}{
+		{name: "valid state", command: command.UpdateUserState{GamesPlayed: 1, Score: 10, DurationMs: 60000, Metadata: json.RawMessage(`{"map": "desert"}`)}},
+		{
+			name:    "too long session and list metadata",
+			command: command.UpdateUserState{DurationMs: 25 * 60 * 60 * 1000, Metadata: json.RawMessage(`[1, 2]`)},
+			expectedFields: []domain.FieldError{
+				{Field: "durationMs", Message: "must not exceed 86400000"},
+				{Field: "metadata", Message: "must be a JSON object"},
+			},
+		},
+		{name: "highest score", command: command.UpdateUserState{GamesPlayed: 1, Score: 2147483647}},
+		{
+			name:    "score out of range",
+			command: command.UpdateUserState{GamesPlayed: 1, Score: 2147483648},
+			expectedFields: []domain.FieldError{
+				{Field: "score", Message: "must not exceed 2147483647"},
+			},
+		},
+		{
+			name:    "huge metadata",
+			command: command.UpdateUserState{Metadata: json.RawMessage(`{"padding": "` + strings.Repeat("a", 5000) + `"}`)},
+			expectedFields: []domain.FieldError{
+				{Field: "metadata", Message: "must not exceed 4096 bytes"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		service := UserServiceImpl{repository: &fakeUserRepository{findUserMock: &domain.User{}}}
+		err := service.UpdateUserState(context.Background(), uuid.Must(uuid.NewV4()), tc.command)
+		if tc.expectedFields == nil && err != nil {
+			t.Errorf("%s: expected no error, actual: %v", tc.name, err)
+		}
+		if fields := validationFields(err); !reflect.DeepEqual(fields, tc.expectedFields) {
+			t.Errorf("%s: expected fields %+v, actual: %+v", tc.name, tc.expectedFields, fields)
+		}
+	}
+}
+
+func TestUserServiceImpl_UpdateFriendsValidation(t *testing.T) {
+	userId, _ := uuid.NewV4()
+	friendId, _ := uuid.NewV4()
+	otherId, _ := uuid.NewV4()
+	missingId, _ := uuid.NewV4()
+	cases := []struct {
+		name           string
+		command        command.UpdateUserFriends
+		patch          bool
+		expectedFields []domain.FieldError
+	}{
+		{name: "valid friends", command: command.UpdateUserFriends{Friends: []uuid.UUID{friendId, otherId}}},
+		{
+			name:    "self, repeated and missing friends",
+			command: command.UpdateUserFriends{Friends: []uuid.UUID{friendId, userId, friendId, missingId}},
+			expectedFields: []domain.FieldError{
+				{Field: "friends[1]", Message: "must not be the user"},
+				{Field: "friends[2]", Message: "must not be repeated"},
+				{Field: "friends[3]", Message: "no user with this id exists"},
+			},
+		},
+		{
+			name:    "added and removed",
+			command: command.UpdateUserFriends{Add: []uuid.UUID{friendId, uuid.Nil}, Remove: []uuid.UUID{friendId}},
+			patch:   true,
+			expectedFields: []domain.FieldError{
+				{Field: "add[1]", Message: "must not be empty"},
+				{Field: "remove[0]", Message: "must not be both added and removed"},
+			},
+		},
+		{
+			name:    "replace without friends",
+			command: command.UpdateUserFriends{Add: []uuid.UUID{friendId}},
+			expectedFields: []domain.FieldError{
+				{Field: "friends", Message: "is required, an empty list removes every friend"},
+				{Field: "add", Message: "is only allowed when patching the friend list"},
+			},
+		},
+		{name: "replace with an empty list", command: command.UpdateUserFriends{Friends: []uuid.UUID{}}},
+		{
+			name:    "patch with friends",
+			command: command.UpdateUserFriends{Friends: []uuid.UUID{friendId}, Remove: []uuid.UUID{otherId}},
+			patch:   true,
+			expectedFields: []domain.FieldError{
+				{Field: "friends", Message: "is only allowed when replacing the friend list"},
+			},
+		},
+		{
+			name:    "too many friends",
+			command: command.UpdateUserFriends{Add: []uuid.UUID{friendId, otherId, missingId}, Remove: []uuid.UUID{userId, uuid.Nil}},
+			patch:   true,
+			expectedFields: []domain.FieldError{
+				{Field: "remove[0]", Message: "must not be the user"},
+				{Field: "remove[1]", Message: "must not be empty"},
+				{Field: "friends", Message: "must not list more than 4 users"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		service := UserServiceImpl{
+			repository: &fakeUserRepository{missingUsersMock: []uuid.UUID{missingId}},
+			config:     Config{MaxFriendsPerRequest: 4},
+		}
+		var err error
+		if tc.patch {
+			_, err = service.PatchUserFriends(context.Background(), userId, tc.command)
+		} else {
+			_, err = service.UpdateUserFriends(context.Background(), userId, tc.command)
+		}
+		if tc.expectedFields == nil && err != nil {
+			t.Errorf("%s: expected no error, actual: %v", tc.name, err)
+		}
+		if fields := validationFields(err); !reflect.DeepEqual(fields, tc.expectedFields) {
+			t.Errorf("%s: expected fields %+v, actual: %+v", tc.name, tc.expectedFields, fields)
+		}
+	}
+}
+
+func TestUserServiceImpl_UpdateFriendsLookupFails(t *testing.T) {
+	lookupErr := domain.UnavailableError("database_unavailable", "the database is unavailable")
+	service := UserServiceImpl{repository: &fakeUserRepository{errMock: lookupErr}}
+
+	_, err := service.PatchUserFriends(context.Background(), uuid.Must(uuid.NewV4()), command.UpdateUserFriends{Add: []uuid.UUID{uuid.Must(uuid.NewV4())}})
+	if !errors.Is(err, lookupErr) {
+		t.Errorf("expected err %v, actual: %v", lookupErr, err)
+	}
+}
+
+func TestUserServiceImpl_UpdateUserProfileValidation(t *testing.T) {
+	text := func(s string) *string { return &s }
+	cases := []struct {
+		name           string
+		command        command.UpdateUserProfile
+		expectedFields []domain.FieldError
+	}{
+		{
+			name:    "valid profile",
+			command: command.UpdateUserProfile{Country: text("BR"), AvatarUrl: text("https://cdn.example.com/a.png"), Bio: text(strings.Repeat("é", 500))},
+		},
+		{name: "cleared fields", command: command.UpdateUserProfile{Country: text(""), AvatarUrl: text(""), Bio: text("")}},
+		{
+			name:    "invalid country",
+			command: command.UpdateUserProfile{Country: text("br")},
+			expectedFields: []domain.FieldError{
+				{Field: "country", Message: "must be an ISO 3166-1 alpha-2 code, such as US"},
+			},
+		},
+		{
+			name:    "country name",
+			command: command.UpdateUserProfile{Country: text("Brazil")},
+			expectedFields: []domain.FieldError{
+				{Field: "country", Message: "must be an ISO 3166-1 alpha-2 code, such as US"},
+			},
+		},
+		{
+			name:    "script avatar url",
+			command: command.UpdateUserProfile{AvatarUrl: text("javascript:alert(1)")},
+			expectedFields: []domain.FieldError{
+				{Field: "avatarUrl", Message: "must be an http or https URL"},
+			},
+		},
+		{
+			name:    "relative avatar url",
+			command: command.UpdateUserProfile{AvatarUrl: text("/avatars/a.png")},
+			expectedFields: []domain.FieldError{
+				{Field: "avatarUrl", Message: "must be an http or https URL"},
+			},
+		},
+		{
+			name:    "huge avatar url",
+			command: command.UpdateUserProfile{AvatarUrl: text("https://cdn.example.com/" + strings.Repeat("a", 2048))},
+			expectedFields: []domain.FieldError{
+				{Field: "avatarUrl", Message: "must not exceed 2048 characters"},
+			},
+		},
+		{
+			name:    "long bio and invalid name",
+			command: command.UpdateUserProfile{Name: text("a"), Bio: text(strings.Repeat("a", 501))},
+			expectedFields: []domain.FieldError{
+				{Field: "name", Message: "must be between 3 and 32 characters long"},
+				{Field: "bio", Message: "must not exceed 500 characters"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		service := UserServiceImpl{repository: &fakeUserRepository{findUserMock: &domain.User{Name: "Jessica"}}}
+		_, err := service.UpdateUserProfile(context.Background(), uuid.Must(uuid.NewV4()), tc.command)
+		if tc.expectedFields == nil && err != nil {
+			t.Errorf("%s: expected no error, actual: %v", tc.name, err)
+		}
+		if fields := validationFields(err); !reflect.DeepEqual(fields, tc.expectedFields) {
+			t.Errorf("%s: expected fields %+v, actual: %+v", tc.name, tc.expectedFields, fields)
+		}
+	}
+}
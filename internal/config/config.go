@@ -0,0 +1,387 @@
+// Package config loads the application settings. Every setting starts with
+// the default of the selected profile and can be overridden, from the lowest
+// to the highest precedence, by a YAML file, an environment variable and a
+// command-line flag.
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+type Profile string
+
+const (
+	ProfileDev  Profile = "dev"
+	ProfileTest Profile = "test"
+	ProfileProd Profile = "prod"
+)
+
+// TracingExporter selects where the spans are sent.
+type TracingExporter string
+
+const (
+	TracingNone   TracingExporter = "none"
+	TracingStdout TracingExporter = "stdout"
+	TracingOTLP   TracingExporter = "otlp"
+)
+
+// Storage selects the UserRepository implementation. The memory storage
+// needs no database and loses every change on restart.
+type Storage string
+
+const (
+	StoragePostgres Storage = "postgres"
+	StorageMemory   Storage = "memory"
+)
+
+type Config struct {
+	Profile     Profile     `yaml:"-"`
+	Storage     Storage     `yaml:"storage"`
+	HTTP        HTTP        `yaml:"http"`
+	Database    Database    `yaml:"database"`
+	Leaderboard Leaderboard `yaml:"leaderboard"`
+	Users       Users       `yaml:"users"`
+	Auth        Auth        `yaml:"auth"`
+	GameServers GameServers `yaml:"gameServers"`
+	Tracing     Tracing     `yaml:"tracing"`
+	Logging     Logging     `yaml:"logging"`
+	RateLimits  RateLimits  `yaml:"rateLimits"`
+}
+
+type HTTP struct {
+	Addr              string        `yaml:"addr"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+	ReadTimeout       time.Duration `yaml:"readTimeout"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout"`
+	// DrainDelay is how long the server keeps accepting requests after the
+	// readiness probe starts failing, so that load balancers stop routing
+	// traffic to it before it closes its listener.
+	DrainDelay time.Duration `yaml:"drainDelay"`
+	// ShutdownTimeout is how long in-flight requests are given to finish
+	// once the server is asked to stop.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+	MaxBodyBytes    int64         `yaml:"maxBodyBytes"`
+	// ValidateRequests rejects the requests that do not match the OpenAPI
+	// document before they reach the handlers.
+	ValidateRequests bool `yaml:"validateRequests"`
+}
+
+type Database struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslMode"`
+	// MaxConns limits the size of the connection pool, the pgx default is
+	// used when it is 0.
+	MaxConns int `yaml:"maxConns"`
+	// ReadTimeout and WriteTimeout bound every query and transaction, on top
+	// of the request deadline. No bound is added when they are 0.
+	ReadTimeout  time.Duration `yaml:"readTimeout"`
+	WriteTimeout time.Duration `yaml:"writeTimeout"`
+	// Migrations is the directory the migration files are read from.
+	Migrations string `yaml:"migrations"`
+}
+
+// URL returns the connection string of the database.
+func (d Database) URL() string {
+	u := url.URL{
+		Scheme:   "postgresql",
+		User:     url.UserPassword(d.User, d.Password),
+		Host:     net.JoinHostPort(d.Host, strconv.Itoa(d.Port)),
+		Path:     "/" + d.Name,
+		RawQuery: url.Values{"sslmode": {d.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
+type Leaderboard struct {
+	// Timezone is the IANA name of the timezone periodic leaderboards
+	// reset in.
+	Timezone string `yaml:"timezone"`
+}
+
+type Users struct {
+	RenameCooldown time.Duration `yaml:"renameCooldown"`
+	// MaxFriendsPerRequest bounds the friend ids a single friends update
+	// may carry.
+	MaxFriendsPerRequest int `yaml:"maxFriendsPerRequest"`
+}
+
+type Auth struct {
+	// SigningKeys holds comma separated "kid:secret" pairs. An ephemeral key
+	// is generated when it is empty, outside of the prod profile.
+	SigningKeys string        `yaml:"signingKeys"`
+	ActiveKid   string        `yaml:"activeKid"`
+	TokenTTL    time.Duration `yaml:"tokenTTL"`
+	AdminIds    []string      `yaml:"adminIds"`
+}
+
+type GameServers struct {
+	// Secrets holds comma separated "serverId:secret" pairs.
+	Secrets      string        `yaml:"secrets"`
+	SignedRoutes []string      `yaml:"signedRoutes"`
+	MaxSkew      time.Duration `yaml:"maxSkew"`
+}
+
+type Tracing struct {
+	Exporter TracingExporter `yaml:"exporter"`
+	// Endpoint is the host:port of the collector receiving OTLP over HTTP.
+	Endpoint string `yaml:"endpoint"`
+}
+
+type Logging struct {
+	// Level is the least severe level logged, it can be changed at runtime
+	// through the admin routes.
+	Level string `yaml:"level"`
+	// Format is json, for log collectors, or text, for humans.
+	Format string `yaml:"format"`
+}
+
+type RateLimits struct {
+	// Store keeps the buckets in memory, for a single instance, or in
+	// postgres, to share them between instances.
+	Store Storage `yaml:"store"`
+	// Routes holds routeName:requests/period[:burst] entries, routes left out
+	// are not limited.
+	Routes []string `yaml:"routes"`
+}
+
+// Defaults returns the settings of the profile before anything is
+// overridden. The prod profile has no database password and requires TLS.
+func Defaults(profile Profile) (*Config, error) {
+	cfg := Config{
+		Profile: profile,
+		Storage: StoragePostgres,
+		HTTP: HTTP{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       10 * time.Second,
+			WriteTimeout:      10 * time.Second,
+			IdleTimeout:       time.Minute,
+			DrainDelay:        5 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+			MaxBodyBytes:      1 << 20,
+		},
+		Database: Database{
+			Host:         "localhost",
+			Port:         5432,
+			User:         "root",
+			Password:     "root",
+			Name:         "game",
+			SSLMode:      "disable",
+			ReadTimeout:  3 * time.Second,
+			WriteTimeout: 5 * time.Second,
+			Migrations:   "data/migrations",
+		},
+		Leaderboard: Leaderboard{Timezone: "UTC"},
+		Users:       Users{RenameCooldown: 30 * 24 * time.Hour, MaxFriendsPerRequest: 100},
+		Auth:        Auth{TokenTTL: time.Hour},
+		GameServers: GameServers{MaxSkew: 5 * time.Minute},
+		Tracing:     Tracing{Exporter: TracingNone, Endpoint: "localhost:4318"},
+		Logging:     Logging{Level: "info", Format: "json"},
+		RateLimits: RateLimits{
+			Store:  StorageMemory,
+			Routes: []string{"updateUserState:60/1m", "sendFriendRequest:30/1m"},
+		},
+	}
+
+	switch profile {
+	case ProfileDev:
+	case ProfileTest:
+		cfg.Database.Name = "game_test"
+	case ProfileProd:
+		cfg.Database.Password = ""
+		cfg.Database.SSLMode = "require"
+	default:
+		return nil, fmt.Errorf("unknown profile: %s", profile)
+	}
+
+	return &cfg, nil
+}
+
+// Load builds the configuration from the command-line arguments and the
+// environment. The profile is selected with -profile or the profile
+// variable, and the YAML file with -config or the configFile variable.
+func Load(args []string, getenv func(string) string) (*Config, error) {
+	type assignment struct {
+		setting  setting
+		value    string
+		fromFile bool
+	}
+	var assignments []assignment
+
+	fs := flag.NewFlagSet("game-project", flag.ContinueOnError)
+	profile := fs.String("profile", "", "settings profile, one of dev, test or prod")
+	file := fs.String("config", "", "path of a YAML configuration file")
+	for _, s := range settings {
+		s := s
+		fs.Func(s.flag, s.usage, func(value string) error {
+			assignments = append(assignments, assignment{setting: s, value: value})
+			return nil
+		})
+		if s.secret {
+			fs.Func(s.flag+"-file", "file to read the "+s.flag+" from", func(path string) error {
+				assignments = append(assignments, assignment{setting: s, value: path, fromFile: true})
+				return nil
+			})
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg, err := Defaults(Profile(firstNonEmpty(*profile, getenv("profile"), string(ProfileDev))))
+	if err != nil {
+		return nil, err
+	}
+
+	if path := firstNonEmpty(*file, getenv("configFile")); path != "" {
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the configuration file: %w", err)
+		}
+		if err := yaml.UnmarshalStrict(raw, cfg); err != nil {
+			return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+		}
+	}
+
+	for _, s := range settings {
+		if path := getenv(s.env + "_FILE"); s.secret && path != "" {
+			err = s.apply(cfg, path, true)
+		} else if value := getenv(s.env); value != "" {
+			err = s.apply(cfg, value, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, a := range assignments {
+		if err := a.setting.apply(cfg, a.value, a.fromFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Validate checks that the settings are usable, so that the application
+// fails on startup rather than on the first request.
+func (c *Config) Validate() error {
+	switch c.Storage {
+	case StoragePostgres, StorageMemory:
+	default:
+		return fmt.Errorf("unknown storage: %s", c.Storage)
+	}
+	switch c.Tracing.Exporter {
+	case TracingNone, TracingStdout:
+	case TracingOTLP:
+		if c.Tracing.Endpoint == "" {
+			return fmt.Errorf("the otlp tracing exporter requires an endpoint")
+		}
+	default:
+		return fmt.Errorf("unknown tracing exporter: %s", c.Tracing.Exporter)
+	}
+	switch c.Logging.Level {
+	case "panic", "fatal", "error", "warn", "warning", "info", "debug", "trace":
+	default:
+		return fmt.Errorf("unknown log level: %s", c.Logging.Level)
+	}
+	if c.Logging.Format != "json" && c.Logging.Format != "text" {
+		return fmt.Errorf("unknown log format: %s", c.Logging.Format)
+	}
+	switch c.RateLimits.Store {
+	case StorageMemory:
+	case StoragePostgres:
+		if c.Storage != StoragePostgres {
+			return fmt.Errorf("the postgres rate limit store requires the postgres storage")
+		}
+	default:
+		return fmt.Errorf("unknown rate limit store: %s", c.RateLimits.Store)
+	}
+	if c.HTTP.Addr == "" {
+		return fmt.Errorf("the HTTP address must not be empty")
+	}
+	if c.HTTP.ReadHeaderTimeout <= 0 || c.HTTP.ReadTimeout <= 0 || c.HTTP.WriteTimeout <= 0 || c.HTTP.IdleTimeout <= 0 {
+		return fmt.Errorf("the HTTP timeouts must be positive")
+	}
+	if c.HTTP.DrainDelay < 0 {
+		return fmt.Errorf("the HTTP drain delay must not be negative")
+	}
+	if c.HTTP.ShutdownTimeout <= 0 {
+		return fmt.Errorf("the HTTP shutdown timeout must be positive")
+	}
+	if c.HTTP.MaxBodyBytes <= 0 {
+		return fmt.Errorf("the HTTP max body size must be positive")
+	}
+	if c.Database.Host == "" || c.Database.User == "" || c.Database.Name == "" {
+		return fmt.Errorf("the database host, user and name must not be empty")
+	}
+	if c.Database.Port < 1 || c.Database.Port > 65535 {
+		return fmt.Errorf("invalid database port: %d", c.Database.Port)
+	}
+	switch c.Database.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		return fmt.Errorf("invalid database SSL mode: %s", c.Database.SSLMode)
+	}
+	if c.Database.MaxConns < 0 {
+		return fmt.Errorf("the database max connections must not be negative")
+	}
+	if c.Database.ReadTimeout < 0 || c.Database.WriteTimeout < 0 {
+		return fmt.Errorf("the database timeouts must not be negative")
+	}
+	if _, err := time.LoadLocation(c.Leaderboard.Timezone); err != nil {
+		return fmt.Errorf("invalid leaderboard timezone: %w", err)
+	}
+	if c.Users.RenameCooldown < 0 {
+		return fmt.Errorf("the rename cooldown must not be negative")
+	}
+	if c.Users.MaxFriendsPerRequest <= 0 {
+		return fmt.Errorf("the max friends per request must be positive")
+	}
+	if c.Auth.TokenTTL <= 0 {
+		return fmt.Errorf("the token TTL must be positive")
+	}
+	if c.GameServers.MaxSkew <= 0 {
+		return fmt.Errorf("the signature max skew must be positive")
+	}
+
+	if c.Profile == ProfileProd {
+		if c.Storage != StoragePostgres {
+			return fmt.Errorf("the prod profile requires the postgres storage")
+		}
+		if c.Database.Password == "" {
+			return fmt.Errorf("the prod profile requires a database password")
+		}
+		if c.Auth.SigningKeys == "" {
+			return fmt.Errorf("the prod profile requires token signing keys")
+		}
+	}
+
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
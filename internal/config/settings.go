@@ -0,0 +1,174 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting binds a field of the Config to its environment variable and its
+// command-line flag. Secrets can also be read from a file, named by the
+// variable with a _FILE suffix or by the flag with a -file suffix.
+type setting struct {
+	env    string
+	flag   string
+	usage  string
+	secret bool
+	set    func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{env: "storage", flag: "storage", usage: "where users are stored, postgres or memory",
+		set: setString(func(c *Config) *string { return (*string)(&c.Storage) })},
+	{env: "httpAddr", flag: "http-addr", usage: "address the HTTP server listens on",
+		set: setString(func(c *Config) *string { return &c.HTTP.Addr })},
+	{env: "httpReadHeaderTimeout", flag: "http-read-header-timeout", usage: "time allowed to read the request headers",
+		set: setDuration(func(c *Config) *time.Duration { return &c.HTTP.ReadHeaderTimeout })},
+	{env: "httpReadTimeout", flag: "http-read-timeout", usage: "time allowed to read a whole request",
+		set: setDuration(func(c *Config) *time.Duration { return &c.HTTP.ReadTimeout })},
+	{env: "httpWriteTimeout", flag: "http-write-timeout", usage: "time allowed to write a response",
+		set: setDuration(func(c *Config) *time.Duration { return &c.HTTP.WriteTimeout })},
+	{env: "httpIdleTimeout", flag: "http-idle-timeout", usage: "time an idle keep-alive connection is kept open",
+		set: setDuration(func(c *Config) *time.Duration { return &c.HTTP.IdleTimeout })},
+	{env: "httpDrainDelay", flag: "http-drain-delay", usage: "time new requests are still served on shutdown, once the readiness probe fails",
+		set: setDuration(func(c *Config) *time.Duration { return &c.HTTP.DrainDelay })},
+	{env: "httpShutdownTimeout", flag: "http-shutdown-timeout", usage: "time in-flight requests get to finish on shutdown",
+		set: setDuration(func(c *Config) *time.Duration { return &c.HTTP.ShutdownTimeout })},
+	{env: "httpMaxBodyBytes", flag: "http-max-body-bytes", usage: "largest request body accepted, in bytes",
+		set: setInt64(func(c *Config) *int64 { return &c.HTTP.MaxBodyBytes })},
+	{env: "httpValidateRequests", flag: "http-validate-requests", usage: "reject requests that do not match the OpenAPI document, true or false",
+		set: setBool(func(c *Config) *bool { return &c.HTTP.ValidateRequests })},
+	{env: "pgHost", flag: "pg-host", usage: "database host",
+		set: setString(func(c *Config) *string { return &c.Database.Host })},
+	{env: "pgPort", flag: "pg-port", usage: "database port",
+		set: setInt(func(c *Config) *int { return &c.Database.Port })},
+	{env: "pgUser", flag: "pg-user", usage: "database user",
+		set: setString(func(c *Config) *string { return &c.Database.User })},
+	{env: "pgPassword", flag: "pg-password", usage: "database password", secret: true,
+		set: setString(func(c *Config) *string { return &c.Database.Password })},
+	{env: "pgDatabase", flag: "pg-database", usage: "database name",
+		set: setString(func(c *Config) *string { return &c.Database.Name })},
+	{env: "pgSslMode", flag: "pg-sslmode", usage: "database SSL mode",
+		set: setString(func(c *Config) *string { return &c.Database.SSLMode })},
+	{env: "pgMaxConns", flag: "pg-max-conns", usage: "maximum size of the database connection pool",
+		set: setInt(func(c *Config) *int { return &c.Database.MaxConns })},
+	{env: "pgReadTimeout", flag: "pg-read-timeout", usage: "time a database read may take, 0 for no limit",
+		set: setDuration(func(c *Config) *time.Duration { return &c.Database.ReadTimeout })},
+	{env: "pgWriteTimeout", flag: "pg-write-timeout", usage: "time a database write may take, 0 for no limit",
+		set: setDuration(func(c *Config) *time.Duration { return &c.Database.WriteTimeout })},
+	{env: "migrationsPath", flag: "migrations-path", usage: "directory of the database migrations",
+		set: setString(func(c *Config) *string { return &c.Database.Migrations })},
+	{env: "leaderboardTimezone", flag: "leaderboard-timezone", usage: "timezone periodic leaderboards reset in",
+		set: setString(func(c *Config) *string { return &c.Leaderboard.Timezone })},
+	{env: "renameCooldown", flag: "rename-cooldown", usage: "time users have to wait between name changes",
+		set: setDuration(func(c *Config) *time.Duration { return &c.Users.RenameCooldown })},
+	{env: "maxFriendsPerRequest", flag: "max-friends-per-request", usage: "friend ids a single friends update may carry",
+		set: setInt(func(c *Config) *int { return &c.Users.MaxFriendsPerRequest })},
+	{env: "authSigningKeys", flag: "auth-signing-keys", usage: "comma separated kid:secret token signing keys", secret: true,
+		set: setString(func(c *Config) *string { return &c.Auth.SigningKeys })},
+	{env: "authActiveKid", flag: "auth-active-kid", usage: "id of the key new tokens are signed with",
+		set: setString(func(c *Config) *string { return &c.Auth.ActiveKid })},
+	{env: "authTokenTTL", flag: "auth-token-ttl", usage: "how long tokens are valid",
+		set: setDuration(func(c *Config) *time.Duration { return &c.Auth.TokenTTL })},
+	{env: "authAdminIds", flag: "auth-admin-ids", usage: "comma separated ids of the admin users",
+		set: setList(func(c *Config) *[]string { return &c.Auth.AdminIds })},
+	{env: "gameServerSecrets", flag: "game-server-secrets", usage: "comma separated serverId:secret pairs", secret: true,
+		set: setString(func(c *Config) *string { return &c.GameServers.Secrets })},
+	{env: "signedRoutes", flag: "signed-routes", usage: "comma separated names of the routes that require a server signature",
+		set: setList(func(c *Config) *[]string { return &c.GameServers.SignedRoutes })},
+	{env: "signatureMaxSkew", flag: "signature-max-skew", usage: "how far a signature timestamp may be from the server clock",
+		set: setDuration(func(c *Config) *time.Duration { return &c.GameServers.MaxSkew })},
+	{env: "tracingExporter", flag: "tracing-exporter", usage: "where spans are sent, none, stdout or otlp",
+		set: setString(func(c *Config) *string { return (*string)(&c.Tracing.Exporter) })},
+	{env: "tracingEndpoint", flag: "tracing-endpoint", usage: "host:port of the OTLP/HTTP collector",
+		set: setString(func(c *Config) *string { return &c.Tracing.Endpoint })},
+	{env: "logLevel", flag: "log-level", usage: "least severe level logged, from trace to panic",
+		set: setString(func(c *Config) *string { return &c.Logging.Level })},
+	{env: "logFormat", flag: "log-format", usage: "format of the logs, json or text",
+		set: setString(func(c *Config) *string { return &c.Logging.Format })},
+	{env: "rateLimitStore", flag: "rate-limit-store", usage: "where rate limit buckets are kept, memory or postgres",
+		set: setString(func(c *Config) *string { return (*string)(&c.RateLimits.Store) })},
+	{env: "rateLimits", flag: "rate-limits", usage: "comma separated routeName:requests/period[:burst] limits",
+		set: setList(func(c *Config) *[]string { return &c.RateLimits.Routes })},
+}
+
+// apply sets the value, or the content of the file it names, on the Config.
+func (s setting) apply(c *Config, value string, fromFile bool) error {
+	if fromFile {
+		raw, err := ioutil.ReadFile(value)
+		if err != nil {
+			return fmt.Errorf("could not read %s from file: %w", s.env, err)
+		}
+		value = strings.TrimRight(string(raw), "\r\n")
+	}
+	if err := s.set(c, value); err != nil {
+		return fmt.Errorf("invalid %s: %w", s.env, err)
+	}
+	return nil
+}
+
+func setString(field func(c *Config) *string) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func setInt(field func(c *Config) *int) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func setInt64(field func(c *Config) *int64) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func setBool(field func(c *Config) *bool) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func setDuration(field func(c *Config) *time.Duration) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+func setList(field func(c *Config) *[]string) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*field(c) = items
+		return nil
+	}
+}
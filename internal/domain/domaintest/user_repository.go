@@ -0,0 +1,351 @@
+// Package domaintest holds the contract tests every implementation of the
+// domain interfaces has to pass, so that the adapters keep behaving alike.
+package domaintest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"game-project/internal/domain"
+)
+
+// UserRepositoryContract runs the UserRepository contract. newRepository
+// must return an empty repository every time it is called, each case runs
+// against its own one.
+func UserRepositoryContract(t *testing.T, newRepository func(t *testing.T) domain.UserRepository) {
+	cases := []struct {
+		name string
+		test func(t *testing.T, repository domain.UserRepository)
+	}{
+		{"List", testList},
+		{"CreateConflicts", testCreateConflicts},
+		{"UpdateSecret", testUpdateSecret},
+		{"UpdateUserStateKeepsBestScore", testUpdateUserStateKeepsBestScore},
+		{"UpdateFriends", testUpdateFriends},
+		{"FriendLists", testFriendLists},
+		{"PeriodLeaderboard", testPeriodLeaderboard},
+		{"LeaderboardAroundBlocks", testLeaderboardAroundBlocks},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.test(t, newRepository(t))
+		})
+	}
+}
+
+func createUsers(t *testing.T, repository domain.UserRepository, names ...string) []uuid.UUID {
+	t.Helper()
+	var ids []uuid.UUID
+	for _, name := range names {
+		user, err := repository.Create(context.Background(), name, []byte("hash"))
+		if err != nil {
+			t.Fatalf("creating %s: %v", name, err)
+		}
+		ids = append(ids, user.Id)
+	}
+	return ids
+}
+
+func submitScores(t *testing.T, repository domain.UserRepository, ids []uuid.UUID, scores ...uint) {
+	t.Helper()
+	for i, score := range scores {
+		if err := repository.UpdateUserState(context.Background(), ids[i], 1, &domain.GameSession{Score: score}); err != nil {
+			t.Fatalf("submitting %d: %v", score, err)
+		}
+	}
+}
+
+func userNames(usrLst []*domain.User) []string {
+	names := make([]string, 0, len(usrLst))
+	for _, usr := range usrLst {
+		names = append(names, usr.Name)
+	}
+	return names
+}
+
+func sortedNames(usrLst []*domain.User) []string {
+	names := userNames(usrLst)
+	sort.Strings(names)
+	return names
+}
+
+func equalNames(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func wantError(t *testing.T, err error, kind error, code string) {
+	t.Helper()
+	var domainErr *domain.Error
+	if !errors.Is(err, kind) || !errors.As(err, &domainErr) || domainErr.Code != code {
+		t.Errorf("expected a %v error with code %s, actual: %v", kind, code, err)
+	}
+}
+
+func testList(t *testing.T, repository domain.UserRepository) {
+	ctx := context.Background()
+	ids := createUsers(t, repository, "marceline", "jake", "finn", "bubblegum")
+	submitScores(t, repository, ids, 10, 40, 30, 20)
+
+	first, err := repository.List(ctx, domain.UserListing{Sort: domain.UserSortName, Limit: 2})
+	if expected := []string{"bubblegum", "finn"}; err != nil || !equalNames(userNames(first), expected) {
+		t.Fatalf("expected the first page %v, actual: %v, %v", expected, userNames(first), err)
+	}
+	last := first[len(first)-1]
+	second, err := repository.List(ctx, domain.UserListing{
+		Sort:  domain.UserSortName,
+		After: &domain.UserCursor{Name: last.Name, Id: last.Id},
+		Limit: 2,
+	})
+	if expected := []string{"jake", "marceline"}; err != nil || !equalNames(userNames(second), expected) {
+		t.Errorf("expected the second page %v, actual: %v, %v", expected, userNames(second), err)
+	}
+
+	byScore, err := repository.List(ctx, domain.UserListing{
+		Sort:   domain.UserSortScore,
+		Filter: domain.UserFilter{MinScore: 20},
+		Limit:  10,
+	})
+	if expected := []string{"jake", "finn", "bubblegum"}; err != nil || !equalNames(userNames(byScore), expected) {
+		t.Fatalf("expected %v by score, actual: %v, %v", expected, userNames(byScore), err)
+	}
+	afterScore, err := repository.List(ctx, domain.UserListing{
+		Sort:  domain.UserSortScore,
+		After: &domain.UserCursor{Score: byScore[0].Score.Int64, Id: byScore[0].Id},
+		Limit: 1,
+	})
+	if expected := []string{"finn"}; err != nil || !equalNames(userNames(afterScore), expected) {
+		t.Errorf("expected %v after the best score, actual: %v, %v", expected, userNames(afterScore), err)
+	}
+}
+
+func testCreateConflicts(t *testing.T, repository domain.UserRepository) {
+	ctx := context.Background()
+	ids := createUsers(t, repository, "jake", "finn")
+
+	user, err := repository.FindUser(ctx, ids[0])
+	if err != nil || user == nil || user.Name != "jake" || string(user.SecretHash) != "hash" {
+		t.Fatalf("expected to find jake with his secret hash, actual: %+v, %v", user, err)
+	}
+	if missing, err := repository.FindUser(ctx, uuid.Must(uuid.NewV4())); err != nil || missing != nil {
+		t.Errorf("expected no user and no error for an unknown id, actual: %+v, %v", missing, err)
+	}
+
+	_, err = repository.Create(ctx, "jake", []byte("hash"))
+	wantError(t, err, domain.ErrConflict, "name_taken")
+
+	err = repository.UpdateProfile(ctx, &domain.User{Id: ids[1], Name: "jake"})
+	wantError(t, err, domain.ErrConflict, "name_taken")
+	if user, err := repository.FindUser(ctx, ids[1]); err != nil || user.Name != "finn" {
+		t.Errorf("expected the failed rename to keep the name, actual: %+v, %v", user, err)
+	}
+}
+
+func testUpdateSecret(t *testing.T, repository domain.UserRepository) {
+	ctx := context.Background()
+	ids := createUsers(t, repository, "jake")
+
+	if n, err := repository.UpdateSecret(ctx, ids[0], []byte("new hash")); err != nil || n != 1 {
+		t.Fatalf("expected to update the secret of jake, actual: %d, %v", n, err)
+	}
+	if user, err := repository.FindUser(ctx, ids[0]); err != nil || string(user.SecretHash) != "new hash" {
+		t.Errorf("expected the new secret hash, actual: %+v, %v", user, err)
+	}
+	if n, err := repository.UpdateSecret(ctx, uuid.Must(uuid.NewV4()), []byte("hash")); err != nil || n != 0 {
+		t.Errorf("expected no touched rows for an unknown id, actual: %d, %v", n, err)
+	}
+}
+
+func testUpdateUserStateKeepsBestScore(t *testing.T, repository domain.UserRepository) {
+	ctx := context.Background()
+	userId := createUsers(t, repository, "jake")[0]
+
+	var lastId int64
+	for i, score := range []uint{50, 20, 80, 10} {
+		session := &domain.GameSession{Score: score}
+		if err := repository.UpdateUserState(ctx, userId, uint8(i+1), session); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if session.Id <= lastId || session.SubmittedAt.IsZero() {
+			t.Errorf("expected a new session id and the submission time, actual: %+v", session)
+		}
+		lastId = session.Id
+
+		user, err := repository.FindUser(ctx, userId)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if best := []int64{50, 50, 80, 80}[i]; user.Score.Int64 != best || user.GamesPlayed.Int32 != int32(i+1) {
+			t.Errorf("expected score %d after %d games, actual: %d after %d", best, i+1, user.Score.Int64, user.GamesPlayed.Int32)
+		}
+	}
+
+	sessions, err := repository.ListSessions(ctx, userId, nil, 3)
+	if err != nil || len(sessions) != 3 || sessions[0].Score != 10 || sessions[0].UserId != userId {
+		t.Fatalf("expected the 3 latest sessions, latest first, actual: %+v", sessions)
+	}
+	last := sessions[len(sessions)-1]
+	rest, err := repository.ListSessions(ctx, userId, &domain.SessionCursor{SubmittedAt: last.SubmittedAt, Id: last.Id}, 3)
+	if err != nil || len(rest) != 1 || rest[0].Score != 50 {
+		t.Errorf("expected the first session on the next page, actual: %+v, %v", rest, err)
+	}
+
+	err = repository.UpdateUserState(ctx, uuid.Must(uuid.NewV4()), 1, &domain.GameSession{Score: 10})
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected a not found error for a missing user, actual: %v", err)
+	}
+}
+
+func testUpdateFriends(t *testing.T, repository domain.UserRepository) {
+	ctx := context.Background()
+	ids := createUsers(t, repository, "jake", "finn", "bubblegum")
+
+	added, removed, err := repository.UpdateFriends(ctx, ids[0], domain.FriendsUpdate{Add: []uuid.UUID{ids[1], ids[2]}})
+	if err != nil || added != 2 || removed != 0 {
+		t.Fatalf("expected 2 friends added, actual: %d added, %d removed, %v", added, removed, err)
+	}
+	added, _, err = repository.UpdateFriends(ctx, ids[0], domain.FriendsUpdate{Add: []uuid.UUID{ids[1]}})
+	if err != nil || added != 0 {
+		t.Errorf("expected an existing friend not to be added again, actual: %d added, %v", added, err)
+	}
+	if friends, err := repository.ListFriends(ctx, ids[1]); err != nil || !equalNames(userNames(friends), []string{"jake"}) {
+		t.Errorf("expected adding a friend to befriend both ways, actual: %v, %v", userNames(friends), err)
+	}
+
+	_, _, err = repository.UpdateFriends(ctx, ids[0], domain.FriendsUpdate{Add: []uuid.UUID{uuid.Must(uuid.NewV4())}})
+	wantError(t, err, domain.ErrNotFound, "user_not_found")
+
+	added, removed, err = repository.UpdateFriends(ctx, ids[0], domain.FriendsUpdate{Add: []uuid.UUID{ids[1]}, Replace: true})
+	if err != nil || added != 0 || removed != 1 {
+		t.Errorf("expected the replace to remove 1 friend, actual: %d added, %d removed, %v", added, removed, err)
+	}
+	if friends, err := repository.ListFriends(ctx, ids[0]); err != nil || !equalNames(sortedNames(friends), []string{"finn"}) {
+		t.Errorf("expected the friends [finn], actual: %v, %v", sortedNames(friends), err)
+	}
+
+	// Removing a friend ends the friendship in both directions.
+	_, removed, err = repository.UpdateFriends(ctx, ids[0], domain.FriendsUpdate{Remove: []uuid.UUID{ids[1]}})
+	if err != nil || removed != 1 {
+		t.Errorf("expected 1 friend removed, actual: %d removed, %v", removed, err)
+	}
+	if friends, err := repository.ListFriends(ctx, ids[1]); err != nil || len(friends) != 0 {
+		t.Errorf("expected the friendship to end both ways, actual: %v, %v", userNames(friends), err)
+	}
+}
+
+func testFriendLists(t *testing.T, repository domain.UserRepository) {
+	ctx := context.Background()
+	ids := createUsers(t, repository, "jake", "finn", "bubblegum", "marceline")
+	submitScores(t, repository, ids, 30, 50, 10)
+	repository.UpdateFriends(ctx, ids[0], domain.FriendsUpdate{Add: []uuid.UUID{ids[1], ids[2], ids[3]}})
+	if _, err := repository.BlockUser(ctx, ids[0], ids[2]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	friends, err := repository.ListFriends(ctx, ids[0])
+	if expected := []string{"finn", "marceline"}; err != nil || !equalNames(sortedNames(friends), expected) {
+		t.Fatalf("expected the friends %v, actual: %v, %v", expected, sortedNames(friends), err)
+	}
+	for _, friend := range friends {
+		if friend.Id == ids[1] && (!friend.Score.Valid || friend.Score.Int64 != 50) {
+			t.Errorf("expected finn's score of 50, actual: %+v", friend.Score)
+		}
+		if friend.Id == ids[3] && friend.Score.Valid {
+			t.Errorf("expected no score for marceline, actual: %+v", friend.Score)
+		}
+	}
+
+	if blocks, err := repository.ListBlocks(ctx, ids[0]); err != nil || !equalNames(userNames(blocks), []string{"bubblegum"}) {
+		t.Errorf("expected bubblegum to be blocked, actual: %v, %v", userNames(blocks), err)
+	}
+
+	type standing struct {
+		name  string
+		rank  int64
+		delta int64
+	}
+	board, err := repository.FriendsLeaderboard(ctx, nil, ids[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var standings []standing
+	for _, ranked := range board {
+		standings = append(standings, standing{ranked.Name, ranked.Rank, ranked.Delta})
+	}
+	expected := []standing{{"finn", 1, 20}, {"jake", 2, 0}, {"marceline", 3, -30}}
+	if len(standings) != len(expected) {
+		t.Fatalf("expected the standings %+v, actual: %+v", expected, standings)
+	}
+	for i := range expected {
+		if standings[i] != expected[i] {
+			t.Errorf("expected the standings %+v, actual: %+v", expected, standings)
+			break
+		}
+	}
+}
+
+func testPeriodLeaderboard(t *testing.T, repository domain.UserRepository) {
+	ctx := context.Background()
+	ids := createUsers(t, repository, "jake", "finn", "bubblegum")
+	submitScores(t, repository, ids, 30, 50)
+	since := time.Now().Add(-time.Hour)
+
+	board, err := repository.Leaderboard(ctx, &since, uuid.Nil, 10, 0)
+	if expected := []string{"finn", "jake"}; err != nil || !equalNames(rankedNames(board), expected) {
+		t.Errorf("expected only the users with a session in the period %v, actual: %v, %v", expected, rankedNames(board), err)
+	}
+	if around, err := repository.LeaderboardAround(ctx, &since, uuid.Nil, ids[2], 1); err != nil || len(around) != 0 {
+		t.Errorf("expected no ranking around a user without a session in the period, actual: %+v", around)
+	}
+
+	later := time.Now().Add(time.Hour)
+	if ranked, err := repository.Leaderboard(ctx, &later, uuid.Nil, 10, 0); err != nil || len(ranked) != 0 {
+		t.Errorf("expected an empty board without sessions in the period, actual: %+v, %v", ranked, err)
+	}
+	if ranked, err := repository.Leaderboard(ctx, nil, uuid.Nil, 10, 0); err != nil || len(ranked) != 3 {
+		t.Errorf("expected every user on the all-time board, actual: %+v, %v", ranked, err)
+	}
+}
+
+func testLeaderboardAroundBlocks(t *testing.T, repository domain.UserRepository) {
+	ctx := context.Background()
+	ids := createUsers(t, repository, "jake", "finn", "bubblegum")
+	submitScores(t, repository, ids, 30, 20, 10)
+	// Finn's blocks only hide users from finn's own views of the board.
+	if _, err := repository.BlockUser(ctx, ids[1], ids[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repository.BlockUser(ctx, ids[2], ids[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	around, err := repository.LeaderboardAround(ctx, nil, uuid.Nil, ids[1], 1)
+	if expected := []string{"jake", "finn", "bubblegum"}; err != nil || !equalNames(rankedNames(around), expected) {
+		t.Errorf("expected %v around finn for anonymous viewers, actual: %v, %v", expected, rankedNames(around), err)
+	}
+	around, err = repository.LeaderboardAround(ctx, nil, ids[2], ids[1], 1)
+	if expected := []string{"finn", "bubblegum"}; err != nil || !equalNames(rankedNames(around), expected) {
+		t.Errorf("expected %v around finn for bubblegum, actual: %v, %v", expected, rankedNames(around), err)
+	}
+}
+
+func rankedNames(rankedLst []*domain.RankedUser) []string {
+	names := make([]string, 0, len(rankedLst))
+	for _, ranked := range rankedLst {
+		names = append(names, ranked.Name)
+	}
+	return names
+}
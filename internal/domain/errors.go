@@ -0,0 +1,63 @@
+package domain
+
+import "errors"
+
+// The kinds of domain errors. Match them with errors.Is, the adapters use
+// them to pick a response status without knowing every specific error.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrForbidden    = errors.New("forbidden")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrUnavailable  = errors.New("unavailable")
+)
+
+// Error is a domain error of one of the kinds above. Code is a stable,
+// machine readable identifier such as "name_taken", and Fields lists the
+// offending input of validation errors.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Fields  []FieldError
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func NotFoundError(code string, message string) *Error {
+	return &Error{Kind: ErrNotFound, Code: code, Message: message}
+}
+
+func ConflictError(code string, message string) *Error {
+	return &Error{Kind: ErrConflict, Code: code, Message: message}
+}
+
+func ValidationError(code string, message string, fields ...FieldError) *Error {
+	return &Error{Kind: ErrValidation, Code: code, Message: message, Fields: fields}
+}
+
+func ForbiddenError(code string, message string) *Error {
+	return &Error{Kind: ErrForbidden, Code: code, Message: message}
+}
+
+func UnauthorizedError(code string, message string) *Error {
+	return &Error{Kind: ErrUnauthorized, Code: code, Message: message}
+}
+
+// UnavailableError reports a dependency, such as the database, that cannot
+// serve requests for now. Clients may retry later.
+func UnavailableError(code string, message string) *Error {
+	return &Error{Kind: ErrUnavailable, Code: code, Message: message}
+}
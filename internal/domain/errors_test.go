@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestError_Is(t *testing.T) {
+	err := ConflictError("name_taken", "the name is already taken")
+	wrapped := fmt.Errorf("creating user: %w", err)
+
+	if !errors.Is(wrapped, ErrConflict) {
+		t.Error("expected a conflict error")
+	}
+	if !errors.Is(wrapped, err) {
+		t.Error("expected the error to match itself")
+	}
+	if errors.Is(wrapped, ErrNotFound) {
+		t.Error("did not expect a not found error")
+	}
+
+	var domainErr *Error
+	if !errors.As(wrapped, &domainErr) || domainErr.Code != "name_taken" {
+		t.Errorf("expected the code name_taken, actual: %+v", domainErr)
+	}
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type FriendRequestStatus string
+
+const (
+	FriendRequestPending   FriendRequestStatus = "pending"
+	FriendRequestAccepted  FriendRequestStatus = "accepted"
+	FriendRequestDeclined  FriendRequestStatus = "declined"
+	FriendRequestCancelled FriendRequestStatus = "cancelled"
+)
+
+// FriendRequestDirection tells whether friend requests are listed from the
+// point of view of their recipient or of their sender.
+type FriendRequestDirection string
+
+const (
+	FriendRequestIncoming FriendRequestDirection = "incoming"
+	FriendRequestOutgoing FriendRequestDirection = "outgoing"
+)
+
+type FriendRequest struct {
+	Id         uuid.UUID
+	FromUserId uuid.UUID
+	ToUserId   uuid.UUID
+	Status     FriendRequestStatus
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// GameSession is a single game result submitted by a user.
+type GameSession struct {
+	Id          int64
+	UserId      uuid.UUID
+	Score       uint
+	Duration    time.Duration
+	SubmittedAt time.Time
+	Metadata    json.RawMessage
+}
+
+// SessionCursor marks the last GameSession of a page. The next page starts
+// with the session submitted right before it.
+type SessionCursor struct {
+	SubmittedAt time.Time
+	Id          int64
+}
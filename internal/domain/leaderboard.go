@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// LeaderboardPeriod is the time window a leaderboard ranks scores over.
+type LeaderboardPeriod string
+
+const (
+	PeriodDaily   LeaderboardPeriod = "daily"
+	PeriodWeekly  LeaderboardPeriod = "weekly"
+	PeriodMonthly LeaderboardPeriod = "monthly"
+	PeriodAllTime LeaderboardPeriod = "all"
+)
+
+func ParseLeaderboardPeriod(period string) (LeaderboardPeriod, error) {
+	switch p := LeaderboardPeriod(period); p {
+	case PeriodDaily, PeriodWeekly, PeriodMonthly, PeriodAllTime:
+		return p, nil
+	case "":
+		return PeriodAllTime, nil
+	}
+
+	return "", ValidationError("invalid_period", fmt.Sprintf("unknown leaderboard period: %s", period),
+		FieldError{Field: "period", Message: "must be one of daily, weekly, monthly or all"})
+}
+
+// Since returns the moment the period containing now started, with days
+// starting at midnight and weeks on Monday in the given location. It returns
+// nil for the all-time period, which has no start.
+func (p LeaderboardPeriod) Since(now time.Time, loc *time.Location) *time.Time {
+	year, month, day := now.In(loc).Date()
+	var since time.Time
+	switch p {
+	case PeriodDaily:
+		since = time.Date(year, month, day, 0, 0, 0, 0, loc)
+	case PeriodWeekly:
+		weekday := (int(now.In(loc).Weekday()) + 6) % 7
+		since = time.Date(year, month, day-weekday, 0, 0, 0, 0, loc)
+	case PeriodMonthly:
+		since = time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	default:
+		return nil
+	}
+
+	return &since
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeaderboardPeriod_Since(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skip("timezone database not available")
+	}
+	// Monday 2021-08-30 01:30 UTC is still Sunday 2021-08-29 in Sao Paulo.
+	now := time.Date(2021, 8, 30, 1, 30, 0, 0, time.UTC)
+	cases := []struct {
+		period   LeaderboardPeriod
+		loc      *time.Location
+		expected *time.Time
+	}{
+		{period: PeriodDaily, loc: time.UTC, expected: timePtr(time.Date(2021, 8, 30, 0, 0, 0, 0, time.UTC))},
+		{period: PeriodWeekly, loc: time.UTC, expected: timePtr(time.Date(2021, 8, 30, 0, 0, 0, 0, time.UTC))},
+		{period: PeriodMonthly, loc: time.UTC, expected: timePtr(time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC))},
+		{period: PeriodDaily, loc: saoPaulo, expected: timePtr(time.Date(2021, 8, 29, 0, 0, 0, 0, saoPaulo))},
+		{period: PeriodWeekly, loc: saoPaulo, expected: timePtr(time.Date(2021, 8, 23, 0, 0, 0, 0, saoPaulo))},
+		{period: PeriodAllTime, loc: time.UTC, expected: nil},
+	}
+
+	for _, tc := range cases {
+		since := tc.period.Since(now, tc.loc)
+		if tc.expected == nil {
+			if since != nil {
+				t.Errorf("%s: expected no start, actual: %s", tc.period, since)
+			}
+			continue
+		}
+		if since == nil || !since.Equal(*tc.expected) {
+			t.Errorf("%s in %s: expected %s, actual: %v", tc.period, tc.loc, tc.expected, since)
+		}
+	}
+}
+
+func TestParseLeaderboardPeriod(t *testing.T) {
+	if p, err := ParseLeaderboardPeriod(""); err != nil || p != PeriodAllTime {
+		t.Errorf("empty period should default to %s, actual: %s, err: %v", PeriodAllTime, p, err)
+	}
+	if _, err := ParseLeaderboardPeriod("yearly"); err == nil {
+		t.Error("expected err for unknown period")
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
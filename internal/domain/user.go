@@ -1,7 +1,9 @@
 package domain
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
 	"github.com/gofrs/uuid"
 )
@@ -11,13 +13,98 @@ type User struct {
 	Name string `json:"name"`
 	GamesPlayed sql.NullInt32 `json:"gamesPlayed,omitempty"`
 	Score sql.NullInt64 `json:"score,omitempty"`
+	SecretHash []byte `json:"-"`
+	Country sql.NullString `json:"country,omitempty"`
+	AvatarUrl sql.NullString `json:"avatarUrl,omitempty"`
+	Bio sql.NullString `json:"bio,omitempty"`
+	NameChangedAt sql.NullTime `json:"-"`
+	CreatedAt time.Time `json:"-"`
+}
+
+// NameChange records a name a user went by until ChangedAt.
+type NameChange struct {
+	Name      string
+	ChangedAt time.Time
+}
+
+// RankedUser is a User together with its position on the leaderboard.
+// Users with the same score share the same Rank. Delta is the score
+// difference to the user the ranking is relative to, if any.
+type RankedUser struct {
+	User
+	Rank  int64
+	Delta int64
+}
+
+// FriendsUpdate describes a change to a user's friend list. When Replace is
+// set, every current friend that is not in Add is removed.
+type FriendsUpdate struct {
+	Add     []uuid.UUID
+	Remove  []uuid.UUID
+	Replace bool
 }
 
 type UserRepository interface {
-	List() []*User
-	Create(uName string) (*User, error)
-	UpdateUserState(userId uuid.UUID, gamesPlayed uint8, score uint) error
-	FindUser(userId uuid.UUID) *User
-	UpdateFriends(userId uuid.UUID, friendLst []uuid.UUID) (touchedRows int64, err error)
-	ListFriends(userId uuid.UUID) []*User
+	// List returns a page of the users matching the listing filter, in the
+	// listing order, starting right after its cursor when one is given.
+	List(ctx context.Context, listing UserListing) ([]*User, error)
+	Create(ctx context.Context, uName string, secretHash []byte) (*User, error)
+	// UpdateSecret replaces the hash of the secret the user exchanges for
+	// access tokens.
+	UpdateSecret(ctx context.Context, userId uuid.UUID, secretHash []byte) (touchedRows int64, err error)
+	// UpdateUserState records the session and updates the user's aggregate
+	// state in a single transaction, filling in the session's Id and
+	// SubmittedAt.
+	UpdateUserState(ctx context.Context, userId uuid.UUID, gamesPlayed uint8, session *GameSession) error
+	// ListSessions returns the user's most recent sessions first, starting
+	// right after the cursor when one is given.
+	ListSessions(ctx context.Context, userId uuid.UUID, cursor *SessionCursor, limit uint) ([]*GameSession, error)
+	// FindUser returns nil, without an error, when no user has the id.
+	FindUser(ctx context.Context, userId uuid.UUID) (*User, error)
+	// ExistingUsers returns the ids among the given ones that belong to a
+	// user, in no particular order.
+	ExistingUsers(ctx context.Context, userIds []uuid.UUID) ([]uuid.UUID, error)
+	// Search returns the users whose name starts with the term, ignoring
+	// case, followed by the ones with a similar name, best matches first.
+	// Users blocked by the viewer are left out.
+	Search(ctx context.Context, term string, viewerId uuid.UUID, limit uint, offset uint) ([]*User, error)
+	// UpdateProfile stores the name and profile fields of the user. When the
+	// name changes, the previous one is added to the name history.
+	UpdateProfile(ctx context.Context, user *User) error
+	ListNameHistory(ctx context.Context, userId uuid.UUID) ([]*NameChange, error)
+	// Delete removes the user along with their friendships, sessions,
+	// friend requests and blocks.
+	Delete(ctx context.Context, userId uuid.UUID) (touchedRows int64, err error)
+	// UpdateFriends applies the update in a single transaction. Removing a
+	// friend ends the friendship in both directions.
+	UpdateFriends(ctx context.Context, userId uuid.UUID, update FriendsUpdate) (added int64, removed int64, err error)
+	ListFriends(ctx context.Context, userId uuid.UUID) ([]*User, error)
+	// BlockUser also ends any friendship between both users and cancels the
+	// pending friend requests between them.
+	BlockUser(ctx context.Context, userId uuid.UUID, blockedId uuid.UUID) (touchedRows int64, err error)
+	UnblockUser(ctx context.Context, userId uuid.UUID, blockedId uuid.UUID) (touchedRows int64, err error)
+	ListBlocks(ctx context.Context, userId uuid.UUID) ([]*User, error)
+	// BlockedAmong returns the ids of the other users that the user blocked
+	// or was blocked by.
+	BlockedAmong(ctx context.Context, userId uuid.UUID, otherIds []uuid.UUID) ([]uuid.UUID, error)
+	CreateFriendRequest(ctx context.Context, fromUserId uuid.UUID, toUserId uuid.UUID) (*FriendRequest, error)
+	// FindFriendRequest returns nil, without an error, when no friend
+	// request has the id.
+	FindFriendRequest(ctx context.Context, requestId uuid.UUID) (*FriendRequest, error)
+	// ListFriendRequests returns the pending friend requests sent to or by
+	// the user, depending on the direction.
+	ListFriendRequests(ctx context.Context, userId uuid.UUID, direction FriendRequestDirection) ([]*FriendRequest, error)
+	// ResolveFriendRequest moves a pending friend request to the given
+	// status. Accepting it creates the friendship in both directions in the
+	// same transaction. No rows are touched if the request is not pending.
+	ResolveFriendRequest(ctx context.Context, requestId uuid.UUID, status FriendRequestStatus) (touchedRows int64, err error)
+	// Leaderboard rankings consider the best score submitted since the given
+	// time, or the all-time best score when since is nil.
+	// Users blocked by the viewer are left out of the rankings, without
+	// changing the rank of anybody else. Use uuid.Nil for anonymous viewers.
+	Leaderboard(ctx context.Context, since *time.Time, viewerId uuid.UUID, limit uint, offset uint) ([]*RankedUser, error)
+	// LeaderboardAround returns the users ranked within radius positions of
+	// the user, leaving out the users blocked by the viewer.
+	LeaderboardAround(ctx context.Context, since *time.Time, viewerId uuid.UUID, userId uuid.UUID, radius uint) ([]*RankedUser, error)
+	FriendsLeaderboard(ctx context.Context, since *time.Time, userId uuid.UUID) ([]*RankedUser, error)
 }
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// UserSort is the order users are listed in. Names are listed in ascending
+// order, scores and creation dates newest and highest first.
+type UserSort string
+
+const (
+	UserSortName    UserSort = "name"
+	UserSortScore   UserSort = "score"
+	UserSortCreated UserSort = "created"
+)
+
+func ParseUserSort(sort string) (UserSort, error) {
+	switch s := UserSort(sort); s {
+	case UserSortName, UserSortScore, UserSortCreated:
+		return s, nil
+	case "":
+		return UserSortName, nil
+	}
+
+	return "", ValidationError("invalid_sort", fmt.Sprintf("unknown user sort: %s", sort),
+		FieldError{Field: "sort", Message: "must be one of name, score or created"})
+}
+
+// UserCursor is the position of the last user of a page. Only the field of
+// the listing sort is set, the id breaks ties between equal values.
+type UserCursor struct {
+	Name      string
+	Score     int64
+	CreatedAt time.Time
+	Id        uuid.UUID
+}
+
+type UserFilter struct {
+	MinScore       int64
+	MinGamesPlayed int64
+}
+
+// UserListing selects a page of users.
+type UserListing struct {
+	Sort   UserSort
+	Filter UserFilter
+	After  *UserCursor
+	Limit  uint
+}
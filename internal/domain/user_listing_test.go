@@ -0,0 +1,15 @@
+package domain
+
+import "testing"
+
+func TestParseUserSort(t *testing.T) {
+	if s, err := ParseUserSort(""); err != nil || s != UserSortName {
+		t.Errorf("empty sort should default to %s, actual: %s, err: %v", UserSortName, s, err)
+	}
+	if s, err := ParseUserSort("created"); err != nil || s != UserSortCreated {
+		t.Errorf("expected sort %s, actual: %s, err: %v", UserSortCreated, s, err)
+	}
+	if _, err := ParseUserSort("age"); err == nil {
+		t.Error("expected err for unknown sort")
+	}
+}
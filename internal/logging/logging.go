@@ -0,0 +1,30 @@
+// Package logging carries a request-scoped logger through contexts, so that
+// every line logged while serving a request bears its id and route.
+package logging
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type loggerKey struct{}
+
+// NewContext returns a copy of ctx carrying the logger.
+func NewContext(ctx context.Context, logger *log.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the standard logger when
+// there is none.
+func FromContext(ctx context.Context) *log.Entry {
+	if logger, ok := ctx.Value(loggerKey{}).(*log.Entry); ok {
+		return logger
+	}
+	return log.NewEntry(log.StandardLogger())
+}
+
+// WithFields returns a copy of ctx whose logger also logs the fields.
+func WithFields(ctx context.Context, fields log.Fields) context.Context {
+	return NewContext(ctx, FromContext(ctx).WithFields(fields))
+}
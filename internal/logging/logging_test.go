@@ -0,0 +1,21 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestFromContext(t *testing.T) {
+	if logger := FromContext(context.Background()); logger.Logger != log.StandardLogger() || len(logger.Data) != 0 {
+		t.Errorf("expected the standard logger without fields, actual: %+v", logger.Data)
+	}
+
+	ctx := NewContext(context.Background(), log.WithField("request_id", "abc"))
+	ctx = WithFields(ctx, log.Fields{"route": "/user"})
+	logger := FromContext(ctx)
+	if logger.Data["request_id"] != "abc" || logger.Data["route"] != "/user" {
+		t.Errorf("expected the fields of both loggers, actual: %+v", logger.Data)
+	}
+}